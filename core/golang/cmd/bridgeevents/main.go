@@ -0,0 +1,62 @@
+// Command bridgeevents prints the bridge events of a tx as JSON, it replaces
+// scraping the polycli output to get the deposit count of a bridge:
+//
+//	go run ./cmd/bridgeevents -rpc-url $l1_rpc_url -tx-hash $tx_hash -bridge-address $l1_bridge_addr \
+//	    | jq -r '.bridgeEvents[0].depositCount'
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/bridgeevents"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const defaultTimeout = 30 * time.Second
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	rpcURL := flag.String("rpc-url", "http://localhost:8545", "RPC URL of the network the tx was sent to")
+	txHash := flag.String("tx-hash", "", "hash of the tx to decode the events of")
+	bridgeAddress := flag.String("bridge-address", "", "only decode the logs emitted by this bridge address")
+	timeout := flag.Duration("timeout", defaultTimeout, "timeout of the RPC calls")
+	flag.Parse()
+
+	if !isHash(*txHash) {
+		return fmt.Errorf("invalid tx hash %q", *txHash)
+	}
+	var bridgeAddr *common.Address
+	if *bridgeAddress != "" {
+		if !common.IsHexAddress(*bridgeAddress) {
+			return fmt.Errorf("invalid bridge address %q", *bridgeAddress)
+		}
+		addr := common.HexToAddress(*bridgeAddress)
+		bridgeAddr = &addr
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	events, err := bridgeevents.DecodeTxAtURL(ctx, *rpcURL, common.HexToHash(*txHash), bridgeAddr)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(os.Stdout).Encode(events)
+}
+
+func isHash(s string) bool {
+	b, err := hexutil.Decode(s)
+	return err == nil && len(b) == common.HashLength
+}
@@ -0,0 +1,575 @@
+package agglayermock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ScriptedError is the error set on certificates moved to InError by a Script
+const ScriptedError = `{"ScriptedError":"certificate failed by the mock script"}`
+
+// Script returns the statuses a certificate goes through after it is sent.
+// The certificate moves to the next status every time its header is read,
+// so callers polling the agglayer observe every status once.
+type Script func(certificate *agglayer.Certificate) []agglayer.CertificateStatus
+
+// HappyPath is a Script that settles every certificate
+func HappyPath(*agglayer.Certificate) []agglayer.CertificateStatus {
+	return []agglayer.CertificateStatus{agglayer.StatusProven, agglayer.StatusCandidate, agglayer.StatusSettled}
+}
+
+// FailAt returns a Script that follows the happy path up to the given status
+// and then moves the certificate to InError
+func FailAt(status agglayer.CertificateStatus) Script {
+	return func(certificate *agglayer.Certificate) []agglayer.CertificateStatus {
+		var statuses []agglayer.CertificateStatus
+		for _, s := range HappyPath(certificate) {
+			if s == status {
+				break
+			}
+			statuses = append(statuses, s)
+		}
+		return append(statuses, agglayer.StatusInError)
+	}
+}
+
+// Option configures the mock
+type Option func(*Server)
+
+// WithEpochConfiguration sets the result of interop_getEpochConfiguration
+func WithEpochConfiguration(config agglayer.EpochConfiguration) Option {
+	return func(s *Server) {
+		s.epochConfiguration = config
+	}
+}
+
+// WithScript makes the certificates advance on their own following the script
+func WithScript(script Script) Option {
+	return func(s *Server) {
+		s.script = script
+	}
+}
+
+type certificateEntry struct {
+	certificate *agglayer.Certificate
+	header      *agglayer.CertificateHeader
+	// script holds the statuses the certificate still has to go through
+	script []agglayer.CertificateStatus
+}
+
+type networkState struct {
+	known   *certificateEntry
+	pending *certificateEntry
+	settled *certificateEntry
+}
+
+// Server is an in-process agglayer exposing the interop and admin JSON RPC
+// methods. Certificates follow the agglayer state machine, Pending ->
+// Proven -> Candidate -> Settled, and can be moved to InError from any
+// status but Settled. They advance either manually, with Advance, SetStatus,
+// Settle and Fail, or on their own when a Script is configured.
+type Server struct {
+	mu                 sync.Mutex
+	httpServer         *httptest.Server
+	epochConfiguration agglayer.EpochConfiguration
+	script             Script
+
+	certificates map[agglayer.CertificateID]*certificateEntry
+	networks     map[agglayer.NetworkID]*networkState
+	txStatuses   map[common.Hash]agglayer.TxStatus
+	epoch        agglayer.EpochNumber
+	epochIndex   agglayer.CertificateIndex
+}
+
+// New starts the mock, which is closed when the test finishes
+func New(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+
+	s := &Server{
+		epochConfiguration: agglayer.EpochConfiguration{GenesisBlock: 0, EpochDuration: 1},
+		certificates:       map[agglayer.CertificateID]*certificateEntry{},
+		networks:           map[agglayer.NetworkID]*networkState{},
+		txStatuses:         map[common.Hash]agglayer.TxStatus{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// URL returns the URL to send the JSON RPC requests to, both the interop and
+// admin methods are served on it
+func (s *Server) URL() string {
+	return s.httpServer.URL
+}
+
+// Close stops the mock
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// NextEpoch closes the current epoch, the next settled certificates get the
+// new epoch number
+func (s *Server) NextEpoch() agglayer.EpochNumber {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.epoch++
+	s.epochIndex = 0
+	return s.epoch
+}
+
+// Certificate returns a copy of the certificate and its header
+func (s *Server) Certificate(certificateID agglayer.CertificateID) (*agglayer.Certificate, *agglayer.CertificateHeader, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, found := s.certificates[certificateID]
+	if !found {
+		return nil, nil, false
+	}
+	certificate := *entry.certificate
+	header := *entry.header
+	return &certificate, &header, true
+}
+
+// Advance moves the certificate to the next status of the happy path
+func (s *Server) Advance(certificateID agglayer.CertificateID) (*agglayer.CertificateHeader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.entry(certificateID)
+	if err != nil {
+		return nil, err
+	}
+	next, found := nextStatus[entry.header.Status]
+	if !found {
+		return nil, fmt.Errorf("certificate %v can't advance from status %v", certificateID.String(), entry.header.Status)
+	}
+	if err := s.setStatus(entry, next, nil); err != nil {
+		return nil, err
+	}
+	header := *entry.header
+	return &header, nil
+}
+
+// SetStatus moves the certificate to the given status, failing if the
+// agglayer state machine doesn't allow the transition
+func (s *Server) SetStatus(certificateID agglayer.CertificateID, status agglayer.CertificateStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.entry(certificateID)
+	if err != nil {
+		return err
+	}
+	return s.setStatus(entry, status, nil)
+}
+
+// Settle advances the certificate through the happy path until it is settled
+func (s *Server) Settle(certificateID agglayer.CertificateID) (*agglayer.CertificateHeader, error) {
+	for {
+		header, err := s.Advance(certificateID)
+		if err != nil {
+			return nil, err
+		}
+		if header.Status == agglayer.StatusSettled {
+			return header, nil
+		}
+	}
+}
+
+// Fail moves the certificate to InError with the given error details, which
+// are encoded as JSON
+func (s *Server) Fail(certificateID agglayer.CertificateID, details any) error {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.entry(certificateID)
+	if err != nil {
+		return err
+	}
+	return s.setStatus(entry, agglayer.StatusInError, encoded)
+}
+
+// nextStatus is the happy path of the agglayer state machine
+var nextStatus = map[agglayer.CertificateStatus]agglayer.CertificateStatus{
+	agglayer.StatusPending:   agglayer.StatusProven,
+	agglayer.StatusProven:    agglayer.StatusCandidate,
+	agglayer.StatusCandidate: agglayer.StatusSettled,
+}
+
+func (s *Server) entry(certificateID agglayer.CertificateID) (*certificateEntry, error) {
+	entry, found := s.certificates[certificateID]
+	if !found {
+		return nil, fmt.Errorf("certificate %v not found", certificateID.String())
+	}
+	return entry, nil
+}
+
+func (s *Server) network(networkID agglayer.NetworkID) *networkState {
+	network, found := s.networks[networkID]
+	if !found {
+		network = &networkState{}
+		s.networks[networkID] = network
+	}
+	return network
+}
+
+// setStatus applies a transition allowed by the state machine
+func (s *Server) setStatus(entry *certificateEntry, status agglayer.CertificateStatus, errorDetails json.RawMessage) error {
+	current := entry.header.Status
+	allowed := nextStatus[current] == status || (status == agglayer.StatusInError && !current.IsFinal())
+	if !allowed {
+		return fmt.Errorf("invalid transition of certificate %v from %v to %v", entry.header.CertificateID.String(), current, status)
+	}
+	s.forceStatus(entry, status, errorDetails)
+	return nil
+}
+
+// forceStatus sets the status without checking the state machine
+func (s *Server) forceStatus(entry *certificateEntry, status agglayer.CertificateStatus, errorDetails json.RawMessage) {
+	entry.header.Status = status
+	entry.header.Error = nil
+	if status == agglayer.StatusInError {
+		entry.header.Error = errorDetails
+	}
+	if status != agglayer.StatusSettled {
+		return
+	}
+
+	epoch, index := s.epoch, s.epochIndex
+	s.epochIndex++
+	txHash := crypto.Keccak256Hash(entry.header.CertificateID.Bytes())
+	entry.header.EpochNumber = &epoch
+	entry.header.CertificateIndex = &index
+	entry.header.SettlementTxHash = &txHash
+	s.txStatuses[txHash] = agglayer.TxStatusDone
+
+	network := s.network(entry.header.NetworkID)
+	network.settled = entry
+	if network.pending == entry {
+		network.pending = nil
+	}
+}
+
+// read returns a copy of the header and moves the certificate to the next
+// status of its script
+func (s *Server) read(entry *certificateEntry) *agglayer.CertificateHeader {
+	if entry == nil {
+		return nil
+	}
+	header := *entry.header
+
+	if len(entry.script) > 0 && !entry.header.Status.IsFinal() {
+		status := entry.script[0]
+		entry.script = entry.script[1:]
+		var errorDetails json.RawMessage
+		if status == agglayer.StatusInError {
+			errorDetails = json.RawMessage(ScriptedError)
+		}
+		if err := s.setStatus(entry, status, errorDetails); err != nil {
+			entry.script = nil
+		}
+	}
+	return &header
+}
+
+// sendCertificate validates and stores a new certificate as pending
+func (s *Server) sendCertificate(certificate *agglayer.Certificate) (agglayer.CertificateID, error) {
+	encoded, err := json.Marshal(certificate)
+	if err != nil {
+		return agglayer.CertificateID{}, err
+	}
+	certificateID := crypto.Keccak256Hash(encoded)
+	if _, found := s.certificates[certificateID]; found {
+		return certificateID, nil
+	}
+
+	network := s.network(certificate.NetworkID)
+	if network.pending != nil && network.pending.header.Status != agglayer.StatusInError {
+		return agglayer.CertificateID{}, engine.NewRPCError(engine.DefaultErrorCode,
+			"certificate %v of network %v is still pending", network.pending.header.CertificateID.String(), certificate.NetworkID)
+	}
+
+	expectedHeight := agglayer.Height(0)
+	if network.settled != nil {
+		expectedHeight = network.settled.header.Height + 1
+		if certificate.PrevLocalExitRoot != network.settled.header.NewLocalExitRoot {
+			return agglayer.CertificateID{}, engine.NewRPCError(engine.InvalidParamsErrorCode,
+				"unexpected previous local exit root %v, expected %v", certificate.PrevLocalExitRoot.String(), network.settled.header.NewLocalExitRoot.String())
+		}
+	}
+	if certificate.Height != expectedHeight {
+		return agglayer.CertificateID{}, engine.NewRPCError(engine.InvalidParamsErrorCode,
+			"unexpected height %v for network %v, expected %v", certificate.Height, certificate.NetworkID, expectedHeight)
+	}
+
+	entry := s.store(certificateID, certificate, agglayer.StatusPending)
+	if s.script != nil {
+		entry.script = s.script(certificate)
+	}
+	return certificateID, nil
+}
+
+// store saves the certificate as the latest known and pending of its network
+func (s *Server) store(certificateID agglayer.CertificateID, certificate *agglayer.Certificate, status agglayer.CertificateStatus) *certificateEntry {
+	entry := &certificateEntry{
+		certificate: certificate,
+		header: &agglayer.CertificateHeader{
+			NetworkID:         certificate.NetworkID,
+			Height:            certificate.Height,
+			CertificateID:     certificateID,
+			PrevLocalExitRoot: certificate.PrevLocalExitRoot,
+			NewLocalExitRoot:  certificate.NewLocalExitRoot,
+			Metadata:          certificate.Metadata,
+			Status:            agglayer.StatusPending,
+		},
+	}
+	s.certificates[certificateID] = entry
+
+	network := s.network(certificate.NetworkID)
+	network.known = entry
+	network.pending = entry
+	if status != agglayer.StatusPending {
+		s.forceStatus(entry, status, nil)
+	}
+	return entry
+}
+
+// handle executes a single JSON RPC request
+func (s *Server) handle(req engine.Request) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch req.Method {
+	case "interop_sendCertificate":
+		var certificate agglayer.Certificate
+		if err := decodeParams(req.Params, &certificate); err != nil {
+			return nil, err
+		}
+		return s.sendCertificate(&certificate)
+
+	case "interop_getCertificateHeader":
+		var certificateID agglayer.CertificateID
+		if err := decodeParams(req.Params, &certificateID); err != nil {
+			return nil, err
+		}
+		entry, found := s.certificates[certificateID]
+		if !found {
+			return nil, engine.NewRPCError(engine.DefaultErrorCode, "certificate %v not found", certificateID.String())
+		}
+		return s.read(entry), nil
+
+	case "interop_getLatestKnownCertificateHeader",
+		"interop_getLatestPendingCertificateHeader",
+		"interop_getLatestSettledCertificateHeader":
+		var networkID agglayer.NetworkID
+		if err := decodeParams(req.Params, &networkID); err != nil {
+			return nil, err
+		}
+		network := s.network(networkID)
+		switch req.Method {
+		case "interop_getLatestKnownCertificateHeader":
+			return s.read(network.known), nil
+		case "interop_getLatestPendingCertificateHeader":
+			return s.read(network.pending), nil
+		default:
+			return s.read(network.settled), nil
+		}
+
+	case "interop_getEpochConfiguration":
+		return s.epochConfiguration, nil
+
+	case "interop_getTxStatus":
+		var txHash common.Hash
+		if err := decodeParams(req.Params, &txHash); err != nil {
+			return nil, err
+		}
+		status, found := s.txStatuses[txHash]
+		if !found {
+			return agglayer.TxStatusNotFound, nil
+		}
+		return status, nil
+
+	case "admin_getCertificate":
+		var certificateID agglayer.CertificateID
+		if err := decodeParams(req.Params, &certificateID); err != nil {
+			return nil, err
+		}
+		entry, found := s.certificates[certificateID]
+		if !found {
+			return nil, engine.NewRPCError(engine.DefaultErrorCode, "certificate %v not found", certificateID.String())
+		}
+		return []any{entry.certificate, entry.header}, nil
+
+	case "admin_setLatestPendingCertificate":
+		var certificateID agglayer.CertificateID
+		if err := decodeParams(req.Params, &certificateID); err != nil {
+			return nil, err
+		}
+		entry, err := s.entry(certificateID)
+		if err != nil {
+			return nil, engine.NewRPCError(engine.DefaultErrorCode, err.Error())
+		}
+		network := s.network(entry.header.NetworkID)
+		network.pending = entry
+		network.known = entry
+		return nil, nil
+
+	case "admin_setLatestProvenCertificate":
+		var certificateID agglayer.CertificateID
+		if err := decodeParams(req.Params, &certificateID); err != nil {
+			return nil, err
+		}
+		entry, err := s.entry(certificateID)
+		if err != nil {
+			return nil, engine.NewRPCError(engine.DefaultErrorCode, err.Error())
+		}
+		s.forceStatus(entry, agglayer.StatusProven, nil)
+		return nil, nil
+
+	case "admin_removePendingCertificate":
+		var (
+			networkID    agglayer.NetworkID
+			height       agglayer.Height
+			removeSoftly bool
+		)
+		if err := decodeParams(req.Params, &networkID, &height, &removeSoftly); err != nil {
+			return nil, err
+		}
+		network := s.network(networkID)
+		if network.pending == nil || network.pending.header.Height != height {
+			return nil, engine.NewRPCError(engine.DefaultErrorCode, "no pending certificate of network %v at height %v", networkID, height)
+		}
+		removed := network.pending
+		network.pending = nil
+		if network.known == removed {
+			network.known = network.settled
+		}
+		if !removeSoftly {
+			delete(s.certificates, removed.header.CertificateID)
+		}
+		return nil, nil
+
+	case "admin_removePendingProof":
+		var certificateID agglayer.CertificateID
+		if err := decodeParams(req.Params, &certificateID); err != nil {
+			return nil, err
+		}
+		entry, err := s.entry(certificateID)
+		if err != nil {
+			return nil, engine.NewRPCError(engine.DefaultErrorCode, err.Error())
+		}
+		if entry.header.Status == agglayer.StatusProven {
+			s.forceStatus(entry, agglayer.StatusPending, nil)
+		}
+		return nil, nil
+
+	case "admin_forcePushPendingCertificate":
+		var (
+			certificate agglayer.Certificate
+			status      agglayer.CertificateStatus
+		)
+		if err := decodeParams(req.Params, &certificate, &status); err != nil {
+			return nil, err
+		}
+		encoded, err := json.Marshal(&certificate)
+		if err != nil {
+			return nil, err
+		}
+		s.store(crypto.Keccak256Hash(encoded), &certificate, status)
+		return nil, nil
+
+	default:
+		return nil, engine.NewRPCError(engine.NotFoundErrorCode, "the method %v does not exist/is not available", req.Method)
+	}
+}
+
+// decodeParams decodes the positional params into the given values
+func decodeParams(params json.RawMessage, values ...any) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(params, &raw); err != nil {
+		return engine.NewRPCError(engine.InvalidParamsErrorCode, "invalid params: %v", err)
+	}
+	if len(raw) < len(values) {
+		return engine.NewRPCError(engine.InvalidParamsErrorCode, "missing params, expected %v got %v", len(values), len(raw))
+	}
+	for i, value := range values {
+		if err := json.Unmarshal(raw[i], value); err != nil {
+			return engine.NewRPCError(engine.InvalidParamsErrorCode, "invalid param %v: %v", i, err)
+		}
+	}
+	return nil
+}
+
+type response struct {
+	JSONRPC string              `json:"jsonrpc"`
+	ID      any                 `json:"id"`
+	Result  any                 `json:"result,omitempty"`
+	Error   *engine.ErrorObject `json:"error,omitempty"`
+}
+
+func (s *Server) respond(req engine.Request) response {
+	result, err := s.handle(req)
+	if err == nil {
+		if result == nil {
+			result = json.RawMessage("null")
+		}
+		return response{JSONRPC: "2.0", ID: req.ID, Result: result}
+	}
+
+	errorObject := &engine.ErrorObject{Code: engine.DefaultErrorCode, Message: err.Error()}
+	var rpcErr *engine.RPCError
+	if errors.As(err, &rpcErr) {
+		errorObject.Code = rpcErr.ErrorCode()
+	}
+	return response{JSONRPC: "2.0", ID: req.ID, Error: errorObject}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result any
+	var requests []engine.Request
+	if err := json.Unmarshal(body, &requests); err == nil {
+		responses := make([]response, len(requests))
+		for i, req := range requests {
+			responses[i] = s.respond(req)
+		}
+		result = responses
+	} else {
+		var req engine.Request
+		if err := json.Unmarshal(body, &req); err != nil {
+			result = response{JSONRPC: "2.0", Error: &engine.ErrorObject{Code: engine.ParserErrorCode, Message: err.Error()}}
+		} else {
+			result = s.respond(req)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
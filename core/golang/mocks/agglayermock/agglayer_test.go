@@ -0,0 +1,173 @@
+package agglayermock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManualLifecycle(t *testing.T) {
+	ctx := context.Background()
+	mock := New(t, WithEpochConfiguration(agglayer.EpochConfiguration{GenesisBlock: 5, EpochDuration: 10}))
+	client := agglayer.NewClient(mock.URL())
+	admin := agglayer.NewAdminClient(mock.URL())
+
+	epochs, err := client.EpochConfiguration(t, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), epochs.EpochDuration)
+
+	latest, err := client.LatestKnownCertificateHeader(t, ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	first := &agglayer.Certificate{NetworkID: 1, Height: 0, NewLocalExitRoot: common.HexToHash("0x1")}
+	firstID, err := client.SendCertificate(t, ctx, first)
+	require.NoError(t, err)
+
+	_, err = client.SendCertificate(t, ctx, &agglayer.Certificate{NetworkID: 1, Height: 1})
+	var rpcErr *engine.RPCError
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Contains(t, rpcErr.Error(), "still pending")
+
+	header, err := client.CertificateHeader(t, ctx, firstID)
+	require.NoError(t, err)
+	assert.Equal(t, agglayer.StatusPending, header.Status)
+
+	require.Error(t, mock.SetStatus(firstID, agglayer.StatusSettled))
+	settled, err := mock.Settle(firstID)
+	require.NoError(t, err)
+	assert.Equal(t, agglayer.StatusSettled, settled.Status)
+	_, err = mock.Advance(firstID)
+	require.Error(t, err)
+
+	header, err = client.LatestSettledCertificateHeader(t, ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, firstID, header.CertificateID)
+	assert.Equal(t, agglayer.EpochNumber(0), *header.EpochNumber)
+	txStatus, err := client.TxStatus(t, ctx, *header.SettlementTxHash)
+	require.NoError(t, err)
+	assert.Equal(t, agglayer.TxStatusDone, txStatus)
+
+	pending, err := client.LatestPendingCertificateHeader(t, ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, pending)
+
+	_, err = client.SendCertificate(t, ctx, &agglayer.Certificate{NetworkID: 1, Height: 2, PrevLocalExitRoot: common.HexToHash("0x1")})
+	require.ErrorContains(t, err, "unexpected height 2")
+	_, err = client.SendCertificate(t, ctx, &agglayer.Certificate{NetworkID: 1, Height: 1})
+	require.ErrorContains(t, err, "unexpected previous local exit root")
+
+	second := &agglayer.Certificate{NetworkID: 1, Height: 1, PrevLocalExitRoot: common.HexToHash("0x1")}
+	secondID, err := client.SendCertificate(t, ctx, second)
+	require.NoError(t, err)
+	require.NoError(t, mock.Fail(secondID, map[string]string{"ProofVerificationFailed": "invalid"}))
+
+	certificate, header, err := admin.GetCertificate(t, ctx, secondID)
+	require.NoError(t, err)
+	assert.Equal(t, agglayer.Height(1), certificate.Height)
+	assert.Equal(t, agglayer.StatusInError, header.Status)
+	assert.JSONEq(t, `{"ProofVerificationFailed":"invalid"}`, string(header.Error))
+
+	// a certificate in error is replaced by a new one at the same height
+	second.Metadata = common.HexToHash("0x2")
+	resentID, err := client.SendCertificate(t, ctx, second)
+	require.NoError(t, err)
+	assert.NotEqual(t, secondID, resentID)
+
+	require.NoError(t, admin.RemovePendingCertificate(t, ctx, 1, 1, false))
+	_, _, found := mock.Certificate(resentID)
+	assert.False(t, found)
+	latest, err = client.LatestKnownCertificateHeader(t, ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, firstID, latest.CertificateID)
+}
+
+func TestScriptedLifecycle(t *testing.T) {
+	ctx := context.Background()
+	previous := agglayer.CertificatePollInterval
+	agglayer.CertificatePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { agglayer.CertificatePollInterval = previous })
+
+	mock := New(t, WithScript(HappyPath))
+	client := agglayer.NewClient(mock.URL())
+
+	_, err := client.SendCertificate(t, ctx, &agglayer.Certificate{NetworkID: 1, Height: 0})
+	require.NoError(t, err)
+
+	header, timeline, err := agglayer.WaitCertificateSettledAtHeight(t, ctx, client, 1, 0, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, agglayer.StatusSettled, header.Status)
+
+	statuses := []agglayer.CertificateStatus{}
+	for _, change := range timeline.Certificate(header.CertificateID) {
+		statuses = append(statuses, change.Status)
+	}
+	assert.Equal(t, []agglayer.CertificateStatus{
+		agglayer.StatusPending, agglayer.StatusProven, agglayer.StatusCandidate, agglayer.StatusSettled,
+	}, statuses)
+}
+
+func TestWaitCertificateWithGlobalIndexSettled(t *testing.T) {
+	ctx := context.Background()
+	previous := agglayer.CertificatePollInterval
+	agglayer.CertificatePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { agglayer.CertificatePollInterval = previous })
+
+	mock := New(t)
+	client := agglayer.NewClient(mock.URL())
+	admin := agglayer.NewAdminClient(mock.URL())
+	globalIndex := agglayer.GlobalIndex{MainnetFlag: true, LeafIndex: 3}
+
+	certificates := []*agglayer.Certificate{
+		{NetworkID: 1, Height: 0, NewLocalExitRoot: common.HexToHash("0x1")},
+		{NetworkID: 1, Height: 1, PrevLocalExitRoot: common.HexToHash("0x1"), NewLocalExitRoot: common.HexToHash("0x2"),
+			ImportedBridgeExits: []agglayer.ImportedBridgeExit{{GlobalIndex: globalIndex}}},
+		{NetworkID: 1, Height: 2, PrevLocalExitRoot: common.HexToHash("0x2")},
+	}
+	ids := make([]agglayer.CertificateID, len(certificates))
+	for i, certificate := range certificates {
+		var err error
+		ids[i], err = client.SendCertificate(t, ctx, certificate)
+		require.NoError(t, err)
+		if i < len(certificates)-1 {
+			_, err = mock.Settle(ids[i])
+			require.NoError(t, err)
+		}
+	}
+
+	// the importing certificate was settled and followed by a newer one
+	// before the wait
+	header, _, err := agglayer.WaitCertificateWithGlobalIndexSettled(t, ctx, client, admin, 1, globalIndex, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, ids[1], header.CertificateID)
+	assert.Equal(t, agglayer.StatusSettled, header.Status)
+}
+
+func TestScriptedFailure(t *testing.T) {
+	ctx := context.Background()
+	previous := agglayer.CertificatePollInterval
+	agglayer.CertificatePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { agglayer.CertificatePollInterval = previous })
+
+	mock := New(t, WithScript(FailAt(agglayer.StatusCandidate)))
+	client := agglayer.NewClient(mock.URL())
+
+	_, err := client.SendCertificate(t, ctx, &agglayer.Certificate{NetworkID: 2, Height: 0})
+	require.NoError(t, err)
+
+	header, _, err := agglayer.WaitPendingCertificateInError(t, ctx, client, 2, time.Second)
+	require.NoError(t, err)
+	assert.JSONEq(t, ScriptedError, string(header.Error))
+
+	err = client.RPC().CallResult(t, ctx, nil, "interop_unknown")
+	var rpcErr *engine.RPCError
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, engine.NotFoundErrorCode, rpcErr.ErrorCode())
+}
@@ -0,0 +1,110 @@
+package bridgeservicemock
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/mocks"
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/agglayer/e2e/core/golang/tools/globalindex"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenMetadata returns the metadata of an ERC20 as the bridge encodes it
+func tokenMetadata(t *testing.T, name, symbol string, decimals uint8) []byte {
+	t.Helper()
+
+	stringType, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	uint8Type, err := abi.NewType("uint8", "", nil)
+	require.NoError(t, err)
+	metadata, err := abi.Arguments{{Type: stringType}, {Type: stringType}, {Type: uint8Type}}.Pack(name, symbol, decimals)
+	require.NoError(t, err)
+	return metadata
+}
+
+func TestServerWithBridgeContract(t *testing.T) {
+	ctx := context.Background()
+	const networkID = 1
+	client, setup := mocks.SimulatedBackend(t, nil, networkID)
+	_, gerContract := mocks.DeployGERManager(t, client, setup)
+	bridge := setup.EBZkevmBridgeProxyContract
+	service := New(t, client.Client(), setup.EBZkevmBridgeProxyAddr, networkID)
+
+	// bridge the gas token out of the network
+	destination := common.HexToAddress("0xde57")
+	auth := *setup.UserAuth
+	auth.Value = big.NewInt(100) //nolint:mnd
+	bridgeTx, err := bridge.BridgeAsset(&auth, 0, destination, auth.Value, common.Address{}, false, nil)
+	require.NoError(t, err)
+	client.Commit()
+
+	bridges := get[bridgeservice.BridgesResult](t, service.URL()+"/bridge/v1/bridges?network_id=1", http.StatusOK)
+	require.Len(t, bridges.Bridges, 1)
+	indexed := bridges.Bridges[0]
+	assert.Equal(t, bridgeTx.Hash(), indexed.TxHash)
+	assert.Equal(t, setup.UserAuth.From, indexed.FromAddress)
+	assert.Equal(t, bridgeTx.Data(), []byte(indexed.Calldata))
+	assert.Equal(t, destination, indexed.DestinationAddress)
+	assert.Equal(t, int64(100), indexed.Amount.Int64())
+	assert.True(t, indexed.IsNativeToken)
+
+	localExitRoot, err := service.LocalExitRoot(ctx)
+	require.NoError(t, err)
+	root, err := bridge.GetRoot(&bind.CallOpts{Context: ctx})
+	require.NoError(t, err)
+	assert.Equal(t, common.Hash(root), localExitRoot)
+
+	// claim a token bridged from mainnet, which deploys its wrapped token
+	token := common.HexToAddress("0x70ce")
+	metadata := tokenMetadata(t, "Token", "TKN", 18) //nolint:mnd
+	amount := big.NewInt(5)                          //nolint:mnd
+	leaf := merkletree.ExitLeafHash(uint8(bridgeservice.LeafTypeAsset), 0, token, networkID, destination, amount, crypto.Keccak256Hash(metadata))
+	mainnetExitTree := merkletree.NewTree(leaf)
+	mainnetExitRoot := mainnetExitTree.Root()
+	proof, err := mainnetExitTree.Proof(0)
+	require.NoError(t, err)
+	var proofLocalExitRoot, proofRollupExitRoot [32][32]byte
+	for i := range proof {
+		proofLocalExitRoot[i] = proof[i]
+	}
+	globalExitRoot := merkletree.GlobalExitRoot(mainnetExitRoot, common.Hash{})
+	_, err = gerContract.UpdateGlobalExitRoot(setup.UserAuth, globalExitRoot)
+	require.NoError(t, err)
+	client.Commit()
+
+	globalIndex := globalindex.New(0, 0)
+	claimTx, err := bridge.ClaimAsset(setup.UserAuth, proofLocalExitRoot, proofRollupExitRoot, globalIndex.Big(),
+		mainnetExitRoot, common.Hash{}, 0, token, networkID, destination, amount, metadata)
+	require.NoError(t, err)
+	client.Commit()
+
+	claims := get[bridgeservice.ClaimsResult](t, service.URL()+"/bridge/v1/claims?network_id=1&global_index="+globalIndex.String(), http.StatusOK)
+	require.Len(t, claims.Claims, 1)
+	claim := claims.Claims[0]
+	assert.Equal(t, claimTx.Hash(), claim.TxHash)
+	assert.Equal(t, token, claim.OriginAddress)
+	assert.Equal(t, int64(5), claim.Amount.Int64())
+	// the fields below are only available in the calldata
+	assert.Equal(t, mainnetExitRoot, claim.MainnetExitRoot)
+	assert.Equal(t, globalExitRoot, claim.GlobalExitRoot)
+	assert.Equal(t, metadata, []byte(claim.Metadata))
+	require.NotNil(t, claim.ProofLocalExitRoot)
+	assert.Equal(t, bridgeservice.Proof(proof), *claim.ProofLocalExitRoot)
+
+	wrapped, err := bridge.GetTokenWrappedAddress(&bind.CallOpts{Context: ctx}, 0, token)
+	require.NoError(t, err)
+	require.NotEqual(t, common.Address{}, wrapped)
+	mappings := get[bridgeservice.TokenMappingsResult](t, service.URL()+"/bridge/v1/token-mappings?network_id=1", http.StatusOK)
+	require.Len(t, mappings.TokenMappings, 1)
+	assert.Equal(t, token, mappings.TokenMappings[0].OriginTokenAddress)
+	assert.Equal(t, wrapped, mappings.TokenMappings[0].WrappedTokenAddress)
+}
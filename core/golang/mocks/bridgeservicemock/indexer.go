@@ -0,0 +1,213 @@
+package bridgeservicemock
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/agglayer/e2e/core/golang/tools/bridgeevents"
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/agglayer/e2e/core/golang/tools/claimcalldata"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// bridgeABI holds the bridge events the mock indexes on top of the ones
+// decoded by bridgeevents, it matches the PolygonZkEVMBridgeV2 contract
+// deployed by mocks.SimulatedBackend
+const bridgeABI = `[
+	{"type":"event","name":"NewWrappedToken","anonymous":false,"inputs":[
+		{"name":"originNetwork","type":"uint32","indexed":false},
+		{"name":"originTokenAddress","type":"address","indexed":false},
+		{"name":"wrappedTokenAddress","type":"address","indexed":false},
+		{"name":"metadata","type":"bytes","indexed":false}]}
+]`
+
+var parsedBridgeABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(bridgeABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// BridgeEventTopic, ClaimEventTopic and NewWrappedTokenTopic are the topics
+// of the indexed events
+var (
+	BridgeEventTopic     = bridgeevents.BridgeEventTopic
+	ClaimEventTopic      = bridgeevents.ClaimEventTopic
+	NewWrappedTokenTopic = parsedBridgeABI.Events["NewWrappedToken"].ID
+)
+
+type newWrappedTokenEvent struct {
+	OriginNetwork       uint32
+	OriginTokenAddress  common.Address
+	WrappedTokenAddress common.Address
+	Metadata            []byte
+}
+
+// BridgeLeafHash returns the hash of the exit tree leaf of the bridge
+func BridgeLeafHash(bridge *bridgeservice.Bridge) common.Hash {
+	var amount *big.Int
+	if bridge.Amount != nil {
+		amount = bridge.Amount.Int
+	}
+	return merkletree.ExitLeafHash(uint8(bridge.LeafType), bridge.OriginNetwork, bridge.OriginAddress,
+		bridge.DestinationNetwork, bridge.DestinationAddress, amount, crypto.Keccak256Hash(bridge.Metadata))
+}
+
+// sync indexes the events emitted since the last synced block
+func (s *Server) sync(ctx context.Context) error {
+	latest, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if s.syncedBlock != nil && latest <= *s.syncedBlock {
+		return nil
+	}
+
+	from := uint64(0)
+	if s.syncedBlock != nil {
+		from = *s.syncedBlock + 1
+	}
+	logs, err := s.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(latest),
+		Addresses: []common.Address{s.bridgeAddr},
+		Topics:    [][]common.Hash{{BridgeEventTopic, ClaimEventTopic, NewWrappedTokenTopic}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// the entries are only kept once the whole range is indexed, so a failed
+	// sync is retried from the same block
+	batch := &indexBatch{}
+	for _, log := range logs {
+		if err := s.index(ctx, log, batch); err != nil {
+			return fmt.Errorf("failed to index log %v of tx %v: %w", log.Index, log.TxHash.String(), err)
+		}
+	}
+	s.bridges = append(s.bridges, batch.bridges...)
+	for _, bridge := range batch.bridges {
+		s.exitTree.Add(bridge.BridgeHash)
+	}
+	s.claims = append(s.claims, batch.claims...)
+	s.tokenMappings = append(s.tokenMappings, batch.tokenMappings...)
+	s.syncedBlock = &latest
+	return nil
+}
+
+// indexBatch holds the entries indexed from a range of blocks
+type indexBatch struct {
+	bridges       []*bridgeservice.Bridge
+	claims        []*bridgeservice.Claim
+	tokenMappings []*bridgeservice.TokenMapping
+}
+
+func (s *Server) index(ctx context.Context, log types.Log, batch *indexBatch) error {
+	header, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(log.BlockNumber))
+	if err != nil {
+		return err
+	}
+	tx, _, err := s.client.TransactionByHash(ctx, log.TxHash)
+	if err != nil {
+		return err
+	}
+	from, err := types.LatestSignerForChainID(tx.ChainId()).Sender(tx)
+	if err != nil {
+		return err
+	}
+
+	switch log.Topics[0] {
+	case BridgeEventTopic:
+		event, err := bridgeevents.DecodeBridgeEvent(&log)
+		if err != nil {
+			return err
+		}
+		bridge := &bridgeservice.Bridge{
+			BlockNum:           log.BlockNumber,
+			BlockPos:           uint64(log.Index),
+			BlockTimestamp:     header.Time,
+			TxHash:             log.TxHash,
+			FromAddress:        from,
+			Calldata:           tx.Data(),
+			LeafType:           event.LeafType,
+			OriginNetwork:      event.OriginNetwork,
+			OriginAddress:      event.OriginAddress,
+			DestinationNetwork: event.DestinationNetwork,
+			DestinationAddress: event.DestinationAddress,
+			Amount:             bridgeservice.NewBigIntString(event.Amount),
+			Metadata:           event.Metadata,
+			DepositCount:       event.DepositCount,
+			IsNativeToken:      event.OriginAddress == (common.Address{}),
+		}
+		bridge.BridgeHash = BridgeLeafHash(bridge)
+		expected := len(s.bridges) + len(batch.bridges)
+		if int(bridge.DepositCount) != expected {
+			return fmt.Errorf("unexpected deposit count %v, expected %v", bridge.DepositCount, expected)
+		}
+		batch.bridges = append(batch.bridges, bridge)
+
+	case ClaimEventTopic:
+		event, err := bridgeevents.DecodeClaimEvent(&log)
+		if err != nil {
+			return err
+		}
+		claim := &bridgeservice.Claim{
+			BlockNum:           log.BlockNumber,
+			BlockTimestamp:     header.Time,
+			TxHash:             log.TxHash,
+			GlobalIndex:        bridgeservice.NewBigIntString(event.GlobalIndex),
+			OriginNetwork:      event.OriginNetwork,
+			OriginAddress:      event.OriginAddress,
+			DestinationNetwork: s.networkID,
+			DestinationAddress: event.DestinationAddress,
+			Amount:             bridgeservice.NewBigIntString(event.Amount),
+			FromAddress:        from,
+		}
+		completeClaim(claim, tx.Data())
+		batch.claims = append(batch.claims, claim)
+
+	case NewWrappedTokenTopic:
+		var event newWrappedTokenEvent
+		if err := parsedBridgeABI.UnpackIntoInterface(&event, "NewWrappedToken", log.Data); err != nil {
+			return err
+		}
+		batch.tokenMappings = append(batch.tokenMappings, &bridgeservice.TokenMapping{
+			BlockNum:            log.BlockNumber,
+			BlockPos:            uint64(log.Index),
+			BlockTimestamp:      header.Time,
+			TxHash:              log.TxHash,
+			OriginNetwork:       event.OriginNetwork,
+			OriginTokenAddress:  event.OriginTokenAddress,
+			WrappedTokenAddress: event.WrappedTokenAddress,
+			Metadata:            event.Metadata,
+		})
+	}
+	return nil
+}
+
+// completeClaim fills the claim fields that are only available in the
+// calldata, it does nothing if the tx didn't call the bridge directly
+func completeClaim(claim *bridgeservice.Claim, calldata []byte) {
+	params, _, err := claimcalldata.Decode(calldata)
+	if err != nil {
+		return
+	}
+
+	proofLocalExitRoot := bridgeservice.Proof(params.ProofLocalExitRoot)
+	proofRollupExitRoot := bridgeservice.Proof(params.ProofRollupExitRoot)
+	claim.ProofLocalExitRoot = &proofLocalExitRoot
+	claim.ProofRollupExitRoot = &proofRollupExitRoot
+	claim.MainnetExitRoot = params.MainnetExitRoot
+	claim.RollupExitRoot = params.RollupExitRoot
+	claim.GlobalExitRoot = merkletree.GlobalExitRoot(params.MainnetExitRoot, params.RollupExitRoot)
+	claim.DestinationNetwork = params.DestinationNetwork
+	claim.Metadata = params.Metadata
+}
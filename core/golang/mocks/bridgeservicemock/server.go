@@ -0,0 +1,488 @@
+package bridgeservicemock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// errNotFound makes the handlers answer with a 404
+var errNotFound = errors.New("not found")
+
+type l1InfoTreeEntry struct {
+	leaf *bridgeservice.L1InfoTreeLeaf
+	// depositCount is the number of bridges included in the leaf
+	depositCount int
+	injected     bool
+}
+
+// Server is an in-process aggkit bridge service for a single network. The
+// bridges, claims and token mappings are indexed from the events emitted by
+// the bridge contract, e.g. the one deployed by mocks.SimulatedBackend, the
+// first time the API is queried after new blocks are committed. The data
+// that doesn't come from that contract, like the L1 info tree or the
+// sovereign chain events, is added by the tests.
+type Server struct {
+	mu         sync.Mutex
+	httpServer *httptest.Server
+	client     simulated.Client
+	bridgeAddr common.Address
+	networkID  uint32
+
+	syncedBlock   *uint64
+	bridges       []*bridgeservice.Bridge
+	claims        []*bridgeservice.Claim
+	tokenMappings []*bridgeservice.TokenMapping
+	exitTree      *merkletree.Tree
+
+	l1InfoTree            []*l1InfoTreeEntry
+	legacyTokenMigrations []*bridgeservice.LegacyTokenMigration
+	removedGERs           []*bridgeservice.RemovedGER
+	setClaims             []*bridgeservice.SetClaim
+	unsetClaims           []*bridgeservice.UnsetClaim
+}
+
+// New starts a bridge service for the network, indexing the bridge contract
+// at the given address. The server is closed when the test finishes.
+func New(t *testing.T, client simulated.Client, bridgeAddr common.Address, networkID uint32) *Server {
+	t.Helper()
+
+	s := &Server{client: client, bridgeAddr: bridgeAddr, networkID: networkID, exitTree: merkletree.NewTree()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(bridgeservice.BridgesPath, s.handle(s.getBridges))
+	mux.HandleFunc(bridgeservice.ClaimsPath, s.handle(s.getClaims))
+	mux.HandleFunc(bridgeservice.ClaimProofPath, s.handle(s.getClaimProof))
+	mux.HandleFunc(bridgeservice.L1InfoTreeIndexPath, s.handle(s.getL1InfoTreeIndex))
+	mux.HandleFunc(bridgeservice.InjectedL1InfoLeafPath, s.handle(s.getInjectedL1InfoLeaf))
+	mux.HandleFunc(bridgeservice.TokenMappingsPath, s.handle(s.getTokenMappings))
+	mux.HandleFunc(bridgeservice.LegacyTokenMigrationsPath, s.handle(s.getLegacyTokenMigrations))
+	mux.HandleFunc(bridgeservice.RemovedGERsPath, s.handle(s.getRemovedGERs))
+	mux.HandleFunc(bridgeservice.SetClaimsPath, s.handle(s.getSetClaims))
+	mux.HandleFunc(bridgeservice.UnsetClaimsPath, s.handle(s.getUnsetClaims))
+
+	s.httpServer = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// URL returns the base URL of the service
+func (s *Server) URL() string {
+	return s.httpServer.URL
+}
+
+// Close stops the service
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// Sync indexes the events emitted since the last sync
+func (s *Server) Sync(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sync(ctx)
+}
+
+// LocalExitRoot returns the root of the local exit tree with all the bridges
+// indexed so far
+func (s *Server) LocalExitRoot(ctx context.Context) (common.Hash, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.sync(ctx); err != nil {
+		return common.Hash{}, err
+	}
+	return s.exitTree.Root(), nil
+}
+
+// AddL1InfoTreeLeaf appends the leaf to the L1 info tree, including all the
+// bridges indexed so far. The index, global exit root and hash are computed
+// by the mock, and on mainnet an empty mainnet exit root is replaced by the
+// local exit root.
+func (s *Server) AddL1InfoTreeLeaf(ctx context.Context, leaf bridgeservice.L1InfoTreeLeaf) (*bridgeservice.L1InfoTreeLeaf, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.sync(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.networkID == 0 && leaf.MainnetExitRoot == (common.Hash{}) {
+		leaf.MainnetExitRoot = s.exitTree.Root()
+	}
+	leaf.L1InfoTreeIndex = uint32(len(s.l1InfoTree))
+	leaf.GlobalExitRoot = merkletree.GlobalExitRoot(leaf.MainnetExitRoot, leaf.RollupExitRoot)
+	leaf.Hash = merkletree.L1InfoTreeLeafHash(leaf.GlobalExitRoot, leaf.PreviousBlockHash, leaf.Timestamp)
+
+	s.l1InfoTree = append(s.l1InfoTree, &l1InfoTreeEntry{leaf: &leaf, depositCount: len(s.bridges)})
+	result := leaf
+	return &result, nil
+}
+
+// InjectL1InfoTreeLeaf marks the leaf as injected in this network, so it is
+// returned by the injected-l1-info-leaf endpoint
+func (s *Server) InjectL1InfoTreeLeaf(index uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if int(index) >= len(s.l1InfoTree) {
+		return fmt.Errorf("L1 info tree leaf %v not found", index)
+	}
+	s.l1InfoTree[index].injected = true
+	return nil
+}
+
+// AddLegacyTokenMigration adds a legacy token migration
+func (s *Server) AddLegacyTokenMigration(migration bridgeservice.LegacyTokenMigration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.legacyTokenMigrations = append(s.legacyTokenMigrations, &migration)
+}
+
+// AddRemovedGER adds a removed global exit root
+func (s *Server) AddRemovedGER(removed bridgeservice.RemovedGER) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removedGERs = append(s.removedGERs, &removed)
+}
+
+// AddSetClaim adds a set claim
+func (s *Server) AddSetClaim(claim bridgeservice.SetClaim) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setClaims = append(s.setClaims, &claim)
+}
+
+// AddUnsetClaim adds an unset claim
+func (s *Server) AddUnsetClaim(claim bridgeservice.UnsetClaim) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unsetClaims = append(s.unsetClaims, &claim)
+}
+
+// handle syncs the events, runs the handler and encodes its result
+func (s *Server) handle(handler func(query *query) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		result, err := func() (any, error) {
+			if err := s.sync(r.Context()); err != nil {
+				return nil, err
+			}
+			return handler(&query{values: r.URL.Query()})
+		}()
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		status := http.StatusOK
+		var badRequest *badRequestError
+		switch {
+		case errors.As(err, &badRequest):
+			status = http.StatusBadRequest
+		case errors.Is(err, errNotFound):
+			status = http.StatusNotFound
+		case err != nil:
+			status = http.StatusInternalServerError
+		}
+		if err != nil {
+			result = bridgeservice.ErrorResponse{Error: err.Error()}
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
+
+func (s *Server) checkNetwork(q *query) error {
+	networkID, err := q.uint("network_id", nil)
+	if err != nil {
+		return err
+	}
+	if networkID != uint64(s.networkID) {
+		return &badRequestError{msg: fmt.Sprintf("unsupported network id %v, this service indexes network %v", networkID, s.networkID)}
+	}
+	return nil
+}
+
+func (s *Server) getBridges(q *query) (any, error) {
+	if err := s.checkNetwork(q); err != nil {
+		return nil, err
+	}
+	depositCount, err := q.optionalUint("deposit_count")
+	if err != nil {
+		return nil, err
+	}
+	fromAddress := q.values.Get("from_address")
+
+	var bridges []*bridgeservice.Bridge
+	for i := len(s.bridges) - 1; i >= 0; i-- {
+		bridge := s.bridges[i]
+		if depositCount != nil && uint64(bridge.DepositCount) != *depositCount {
+			continue
+		}
+		if fromAddress != "" && bridge.FromAddress != common.HexToAddress(fromAddress) {
+			continue
+		}
+		bridges = append(bridges, bridge)
+	}
+
+	page, err := paginate(q, bridges)
+	if err != nil {
+		return nil, err
+	}
+	return bridgeservice.BridgesResult{Bridges: page, Count: len(bridges)}, nil
+}
+
+func (s *Server) getClaims(q *query) (any, error) {
+	if err := s.checkNetwork(q); err != nil {
+		return nil, err
+	}
+	globalIndex := q.values.Get("global_index")
+	fromAddress := q.values.Get("from_address")
+
+	var claims []*bridgeservice.Claim
+	for i := len(s.claims) - 1; i >= 0; i-- {
+		claim := s.claims[i]
+		if globalIndex != "" && claim.GlobalIndex.String() != globalIndex {
+			continue
+		}
+		if fromAddress != "" && claim.FromAddress != common.HexToAddress(fromAddress) {
+			continue
+		}
+		claims = append(claims, claim)
+	}
+
+	page, err := paginate(q, claims)
+	if err != nil {
+		return nil, err
+	}
+	return bridgeservice.ClaimsResult{Claims: page, Count: len(claims)}, nil
+}
+
+func (s *Server) getClaimProof(q *query) (any, error) {
+	if err := s.checkNetwork(q); err != nil {
+		return nil, err
+	}
+	depositCount, err := q.uint("deposit_count", nil)
+	if err != nil {
+		return nil, err
+	}
+	leafIndex, err := q.uint("leaf_index", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if leafIndex >= uint64(len(s.l1InfoTree)) {
+		return nil, fmt.Errorf("L1 info tree leaf %v: %w", leafIndex, errNotFound)
+	}
+	entry := s.l1InfoTree[leafIndex]
+	if depositCount >= uint64(entry.depositCount) {
+		return nil, fmt.Errorf("deposit %v not included in L1 info tree leaf %v: %w", depositCount, leafIndex, errNotFound)
+	}
+
+	proof, err := s.exitTree.ProofAt(uint32(depositCount), uint32(entry.depositCount))
+	if err != nil {
+		return nil, err
+	}
+	// the rollup exit tree isn't modeled, so its proof is left empty
+	return bridgeservice.ClaimProof{
+		ProofLocalExitRoot: bridgeservice.Proof(proof),
+		L1InfoTreeLeaf:     *entry.leaf,
+	}, nil
+}
+
+func (s *Server) getL1InfoTreeIndex(q *query) (any, error) {
+	if err := s.checkNetwork(q); err != nil {
+		return nil, err
+	}
+	depositCount, err := q.uint("deposit_count", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range s.l1InfoTree {
+		if uint64(entry.depositCount) > depositCount {
+			return entry.leaf.L1InfoTreeIndex, nil
+		}
+	}
+	return nil, fmt.Errorf("no L1 info tree leaf includes deposit %v: %w", depositCount, errNotFound)
+}
+
+func (s *Server) getInjectedL1InfoLeaf(q *query) (any, error) {
+	if err := s.checkNetwork(q); err != nil {
+		return nil, err
+	}
+	leafIndex, err := q.uint("leaf_index", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if leafIndex >= uint64(len(s.l1InfoTree)) || !s.l1InfoTree[leafIndex].injected {
+		return nil, fmt.Errorf("injected L1 info tree leaf %v: %w", leafIndex, errNotFound)
+	}
+	return s.l1InfoTree[leafIndex].leaf, nil
+}
+
+func (s *Server) getTokenMappings(q *query) (any, error) {
+	if err := s.checkNetwork(q); err != nil {
+		return nil, err
+	}
+	mappings := slices.Clone(s.tokenMappings)
+	slices.Reverse(mappings)
+	page, err := paginate(q, mappings)
+	if err != nil {
+		return nil, err
+	}
+	return bridgeservice.TokenMappingsResult{TokenMappings: page, Count: len(mappings)}, nil
+}
+
+func (s *Server) getLegacyTokenMigrations(q *query) (any, error) {
+	if err := s.checkNetwork(q); err != nil {
+		return nil, err
+	}
+	migrations := slices.Clone(s.legacyTokenMigrations)
+	slices.Reverse(migrations)
+	page, err := paginate(q, migrations)
+	if err != nil {
+		return nil, err
+	}
+	return bridgeservice.LegacyTokenMigrationsResult{LegacyTokenMigrations: page, Count: len(migrations)}, nil
+}
+
+func (s *Server) getRemovedGERs(q *query) (any, error) {
+	globalExitRoot := q.values.Get("global_exit_root")
+	limit, err := q.uint("limit", ptr(uint64(bridgeservice.DefaultPageSize)))
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []*bridgeservice.RemovedGER
+	for i := len(s.removedGERs) - 1; i >= 0; i-- {
+		ger := s.removedGERs[i]
+		if globalExitRoot != "" && ger.GlobalExitRoot != common.HexToHash(globalExitRoot) {
+			continue
+		}
+		removed = append(removed, ger)
+	}
+	count := len(removed)
+	if uint64(len(removed)) > limit {
+		removed = removed[:limit]
+	}
+	return bridgeservice.RemovedGERsResult{RemovedGERs: removed, Count: count}, nil
+}
+
+func (s *Server) getSetClaims(q *query) (any, error) {
+	globalIndex := q.values.Get("global_index")
+	var claims []*bridgeservice.SetClaim
+	for i := len(s.setClaims) - 1; i >= 0; i-- {
+		claim := s.setClaims[i]
+		if globalIndex == "" || claim.GlobalIndex.String() == globalIndex {
+			claims = append(claims, claim)
+		}
+	}
+	page, err := paginate(q, claims)
+	if err != nil {
+		return nil, err
+	}
+	return bridgeservice.SetClaimsResult{SetClaims: page, Count: len(claims)}, nil
+}
+
+func (s *Server) getUnsetClaims(q *query) (any, error) {
+	globalIndex := q.values.Get("global_index")
+	var claims []*bridgeservice.UnsetClaim
+	for i := len(s.unsetClaims) - 1; i >= 0; i-- {
+		claim := s.unsetClaims[i]
+		if globalIndex == "" || claim.GlobalIndex.String() == globalIndex {
+			claims = append(claims, claim)
+		}
+	}
+	page, err := paginate(q, claims)
+	if err != nil {
+		return nil, err
+	}
+	return bridgeservice.UnsetClaimsResult{UnsetClaims: page, Count: len(claims)}, nil
+}
+
+// paginate returns the page requested with page_number, starting at 1, and
+// page_size
+func paginate[T any](q *query, items []T) ([]T, error) {
+	pageNumber, err := q.uint("page_number", ptr(uint64(1)))
+	if err != nil {
+		return nil, err
+	}
+	pageSize, err := q.uint("page_size", ptr(uint64(bridgeservice.DefaultPageSize)))
+	if err != nil {
+		return nil, err
+	}
+	if pageNumber == 0 || pageSize == 0 || pageSize > bridgeservice.MaxPageSize {
+		return nil, &badRequestError{msg: fmt.Sprintf("invalid page number %v or page size %v", pageNumber, pageSize)}
+	}
+
+	start := (pageNumber - 1) * pageSize
+	if start >= uint64(len(items)) {
+		return []T{}, nil
+	}
+	end := min(start+pageSize, uint64(len(items)))
+	return items[start:end], nil
+}
+
+type badRequestError struct {
+	msg string
+}
+
+// Error returns the error message.
+func (e *badRequestError) Error() string {
+	return e.msg
+}
+
+type query struct {
+	values url.Values
+}
+
+// uint returns the param, or the default value if it is missing. Missing
+// params without a default value are an error.
+func (q *query) uint(name string, defaultValue *uint64) (uint64, error) {
+	value, err := q.optionalUint(name)
+	if err != nil {
+		return 0, err
+	}
+	if value != nil {
+		return *value, nil
+	}
+	if defaultValue != nil {
+		return *defaultValue, nil
+	}
+	return 0, &badRequestError{msg: fmt.Sprintf("missing param %v", name)}
+}
+
+func (q *query) optionalUint(name string) (*uint64, error) {
+	values := q.values[name]
+	if len(values) == 0 || values[0] == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseUint(values[0], 10, 64) //nolint:mnd
+	if err != nil {
+		return nil, &badRequestError{msg: fmt.Sprintf("invalid param %v: %v", name, err)}
+	}
+	return &value, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,192 @@
+package bridgeservicemock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/bridgeevents"
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emitterCode logs its calldata, using the first word as the topic:
+// calldatacopy(0, 0, calldatasize()) log1(32, sub(calldatasize(), 32), mload(0))
+var emitterCode = common.FromHex("0x366000600037600051602036036020a100")
+
+type emitter struct {
+	t        *testing.T
+	backend  *simulated.Backend
+	auth     *bind.TransactOpts
+	contract *bind.BoundContract
+}
+
+func newEmitter(t *testing.T) (*emitter, common.Address) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337)) //nolint:mnd
+	require.NoError(t, err)
+
+	bridgeAddr := common.HexToAddress("0xb41d9e")
+	balance, _ := new(big.Int).SetString("1000000000000000000000", 10) //nolint:mnd
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		auth.From:  {Balance: balance},
+		bridgeAddr: {Code: emitterCode},
+	})
+	t.Cleanup(func() { _ = backend.Close() })
+
+	contract := bind.NewBoundContract(bridgeAddr, abi.ABI{}, backend.Client(), backend.Client(), backend.Client())
+	return &emitter{t: t, backend: backend, auth: auth, contract: contract}, bridgeAddr
+}
+
+func (e *emitter) emit(name string, args ...any) {
+	event, found := parsedBridgeABI.Events[name]
+	if !found {
+		event = bridgeevents.BridgeABI.Events[name]
+	}
+	data, err := event.Inputs.Pack(args...)
+	require.NoError(e.t, err)
+	_, err = e.contract.RawTransact(e.auth, append(event.ID.Bytes(), data...))
+	require.NoError(e.t, err)
+	e.backend.Commit()
+}
+
+func get[T any](t *testing.T, url string, expectedStatus int) T {
+	t.Helper()
+
+	res, err := http.Get(url) //nolint:gosec,noctx
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, expectedStatus, res.StatusCode)
+
+	var result T
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+	return result
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	emitter, bridgeAddr := newEmitter(t)
+	service := New(t, emitter.backend.Client(), bridgeAddr, 0)
+
+	destination := common.HexToAddress("0xde57")
+	token := common.HexToAddress("0x70ce")
+	emitter.emit("BridgeEvent", uint8(0), uint32(0), common.Address{}, uint32(1), destination, big.NewInt(100), []byte{}, uint32(0))
+	emitter.emit("BridgeEvent", uint8(0), uint32(0), token, uint32(1), destination, big.NewInt(200), []byte{0x1}, uint32(1))
+	emitter.emit("BridgeEvent", uint8(1), uint32(0), emitter.auth.From, uint32(1), destination, big.NewInt(0), []byte("message"), uint32(2))
+	emitter.emit("ClaimEvent", big.NewInt(42), uint32(1), token, destination, big.NewInt(5))
+	emitter.emit("NewWrappedToken", uint32(1), token, common.HexToAddress("0x3a9"), []byte{})
+
+	bridges := get[bridgeservice.BridgesResult](t, service.URL()+"/bridge/v1/bridges?network_id=0&page_size=2", http.StatusOK)
+	assert.Equal(t, 3, bridges.Count)
+	require.Len(t, bridges.Bridges, 2)
+	assert.Equal(t, uint32(2), bridges.Bridges[0].DepositCount)
+	assert.Equal(t, bridgeservice.LeafTypeMessage, bridges.Bridges[0].LeafType)
+	assert.Equal(t, emitter.auth.From, bridges.Bridges[0].FromAddress)
+
+	bridges = get[bridgeservice.BridgesResult](t, service.URL()+"/bridge/v1/bridges?network_id=0&page_size=2&page_number=2", http.StatusOK)
+	require.Len(t, bridges.Bridges, 1)
+	first := bridges.Bridges[0]
+	assert.Equal(t, uint32(0), first.DepositCount)
+	assert.True(t, first.IsNativeToken)
+	assert.Equal(t, int64(100), first.Amount.Int64())
+
+	get[bridgeservice.ErrorResponse](t, service.URL()+"/bridge/v1/bridges?network_id=1", http.StatusBadRequest)
+
+	claims := get[bridgeservice.ClaimsResult](t, service.URL()+"/bridge/v1/claims?network_id=0&global_index=42", http.StatusOK)
+	require.Len(t, claims.Claims, 1)
+	assert.Equal(t, token, claims.Claims[0].OriginAddress)
+	assert.Equal(t, int64(5), claims.Claims[0].Amount.Int64())
+
+	mappings := get[bridgeservice.TokenMappingsResult](t, service.URL()+"/bridge/v1/token-mappings?network_id=0", http.StatusOK)
+	require.Len(t, mappings.TokenMappings, 1)
+	assert.Equal(t, common.HexToAddress("0x3a9"), mappings.TokenMappings[0].WrappedTokenAddress)
+
+	// no leaf includes the deposits yet
+	get[bridgeservice.ErrorResponse](t, service.URL()+"/bridge/v1/l1-info-tree-index?network_id=0&deposit_count=1", http.StatusNotFound)
+
+	leaf, err := service.AddL1InfoTreeLeaf(ctx, bridgeservice.L1InfoTreeLeaf{Timestamp: 10})
+	require.NoError(t, err)
+	localExitRoot, err := service.LocalExitRoot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, localExitRoot, leaf.MainnetExitRoot)
+
+	index := get[uint32](t, service.URL()+"/bridge/v1/l1-info-tree-index?network_id=0&deposit_count=1", http.StatusOK)
+	assert.Equal(t, leaf.L1InfoTreeIndex, index)
+
+	url := fmt.Sprintf("%v/bridge/v1/injected-l1-info-leaf?network_id=0&leaf_index=%v", service.URL(), index)
+	get[bridgeservice.ErrorResponse](t, url, http.StatusNotFound)
+	require.NoError(t, service.InjectL1InfoTreeLeaf(index))
+	injected := get[bridgeservice.L1InfoTreeLeaf](t, url, http.StatusOK)
+	assert.Equal(t, *leaf, injected)
+
+	proof := get[bridgeservice.ClaimProof](t, fmt.Sprintf("%v/bridge/v1/claim-proof?network_id=0&deposit_count=1&leaf_index=%v", service.URL(), index), http.StatusOK)
+	assert.Equal(t, *leaf, proof.L1InfoTreeLeaf)
+
+	bridges = get[bridgeservice.BridgesResult](t, service.URL()+"/bridge/v1/bridges?network_id=0&deposit_count=1", http.StatusOK)
+	require.Len(t, bridges.Bridges, 1)
+	assert.True(t, merkletree.VerifyMerkleProof(bridges.Bridges[0].BridgeHash, merkletree.Proof(proof.ProofLocalExitRoot), 1, leaf.MainnetExitRoot))
+
+	service.AddUnsetClaim(bridgeservice.UnsetClaim{GlobalIndex: bridgeservice.NewBigIntString(big.NewInt(42))})
+	unset := get[bridgeservice.UnsetClaimsResult](t, service.URL()+"/bridge/v1/unset-claims?global_index=42", http.StatusOK)
+	assert.Equal(t, 1, unset.Count)
+
+	service.AddRemovedGER(bridgeservice.RemovedGER{GlobalExitRoot: leaf.GlobalExitRoot})
+	removed := get[bridgeservice.RemovedGERsResult](t, service.URL()+"/bridge/v1/removed-gers?global_exit_root="+leaf.GlobalExitRoot.String(), http.StatusOK)
+	require.Len(t, removed.RemovedGERs, 1)
+
+	assert.Equal(t, hexutil.Bytes("message"), get[bridgeservice.BridgesResult](t, service.URL()+"/bridge/v1/bridges?network_id=0&deposit_count=2", http.StatusOK).Bridges[0].Metadata)
+}
+
+// flakyClient fails the lookup of the given tx once
+type flakyClient struct {
+	simulated.Client
+	failTx common.Hash
+	failed bool
+}
+
+func (c *flakyClient) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	if txHash == c.failTx && !c.failed {
+		c.failed = true
+		return nil, false, errors.New("transient failure")
+	}
+	return c.Client.TransactionByHash(ctx, txHash)
+}
+
+func TestSyncRetriesFailedRange(t *testing.T) {
+	ctx := context.Background()
+	emitter, bridgeAddr := newEmitter(t)
+	client := &flakyClient{Client: emitter.backend.Client()}
+	service := New(t, client, bridgeAddr, 0)
+
+	destination := common.HexToAddress("0xde57")
+	emitter.emit("BridgeEvent", uint8(0), uint32(0), common.Address{}, uint32(1), destination, big.NewInt(100), []byte{}, uint32(0))
+	emitter.emit("BridgeEvent", uint8(0), uint32(0), common.Address{}, uint32(1), destination, big.NewInt(200), []byte{}, uint32(1))
+	block, err := emitter.backend.Client().BlockByNumber(ctx, nil)
+	require.NoError(t, err)
+	client.failTx = block.Transactions()[0].Hash()
+
+	require.ErrorContains(t, service.Sync(ctx), "transient failure")
+	require.NoError(t, service.Sync(ctx))
+
+	bridges := get[bridgeservice.BridgesResult](t, service.URL()+"/bridge/v1/bridges?network_id=0", http.StatusOK)
+	assert.Equal(t, 2, bridges.Count)
+	localExitRoot, err := service.LocalExitRoot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, merkletree.NewTree(BridgeLeafHash(bridges.Bridges[1]), BridgeLeafHash(bridges.Bridges[0])).Root(), localExitRoot)
+}
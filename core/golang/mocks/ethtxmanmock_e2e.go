@@ -2,19 +2,24 @@ package mocks
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"testing"
 
-	"github.com/0xPolygon/cdk/log"
-	ethtxtypes "github.com/0xPolygon/zkevm-ethtx-manager/types"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/holiman/uint256"
 	"github.com/stretchr/testify/mock"
 )
 
+// feeMultiplier is the margin applied on the suggested fees, as the
+// ethtxmanager does for blob txs
+const feeMultiplier = 10
+
 func NewEthTxManMock(
 	t *testing.T,
 	client *simulated.Backend,
@@ -22,82 +27,141 @@ func NewEthTxManMock(
 ) *EthTxManagerMock {
 	t.Helper()
 
-	const (
-		ArgToIndex   = 1
-		ArgDataIndex = 3
-		ZeroValue    = 0
-	)
+	ethTxMock, _ := NewEthTxManMockWithStore(t, client, auth)
+	return ethTxMock
+}
+
+// NewEthTxManMockWithStore creates an ethtxmanager mock backed by a
+// MonitoredTxStore, which is returned to tune it and inject failures
+func NewEthTxManMockWithStore(
+	t *testing.T,
+	client *simulated.Backend,
+	auth *bind.TransactOpts,
+) (*EthTxManagerMock, *MonitoredTxStore) {
+	t.Helper()
 
+	store := NewMonitoredTxStore(client, auth)
 	ethTxMock := NewEthTxManagerMock(t)
 	ethTxMock.On(
 		"Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Run(func(args mock.Arguments) {
-			ctx := context.Background()
-			nonce, err := client.Client().PendingNonceAt(ctx, auth.From)
-			if err != nil {
-				log.Error(err)
-				return
-			}
-			gas, err := client.Client().EstimateGas(ctx, ethereum.CallMsg{
-				From:  auth.From,
-				To:    args.Get(ArgToIndex).(*common.Address),
-				Value: big.NewInt(ZeroValue),
-				Data:  args.Get(ArgDataIndex).([]byte),
-			})
-			if err != nil {
-				log.Error(err)
-				res, err := client.Client().CallContract(ctx, ethereum.CallMsg{
-					From:  auth.From,
-					To:    args.Get(ArgToIndex).(*common.Address),
-					Value: big.NewInt(ZeroValue),
-					Data:  args.Get(ArgDataIndex).([]byte),
-				}, nil)
-				log.Debugf("contract call: %s", res)
-				if err != nil {
-					log.Errorf("%+v", err)
-				}
-				return
-			}
-			price, err := client.Client().SuggestGasPrice(ctx)
-			if err != nil {
-				log.Error(err)
-			}
-
-			to, ok := args.Get(ArgToIndex).(*common.Address)
-			if !ok {
-				log.Error("expected *common.Address for ArgToIndex")
-				return
-			}
-			data, ok := args.Get(ArgDataIndex).([]byte)
-			if !ok {
-				log.Error("expected []byte for ArgDataIndex")
-				return
-			}
-			tx := types.NewTx(&types.LegacyTx{
-				To:       to,
-				Nonce:    nonce,
-				Value:    big.NewInt(ZeroValue),
-				Data:     data,
-				Gas:      gas,
-				GasPrice: price,
-			})
-			tx.Gas()
-			signedTx, err := auth.Signer(auth.From, tx)
-			if err != nil {
-				log.Error(err)
-				return
-			}
-			err = client.Client().SendTransaction(ctx, signedTx)
-			if err != nil {
-				log.Error(err)
-				return
-			}
-			client.Commit()
-		}).
-		Return(common.Hash{}, nil)
+		Return(store.Add)
 	// res, err := c.ethTxMan.Result(ctx, id)
-	ethTxMock.On("Result", mock.Anything, mock.Anything).
-		Return(ethtxtypes.MonitoredTxResult{Status: ethtxtypes.MonitoredTxStatusMined}, nil)
+	ethTxMock.On("Result", mock.Anything, mock.Anything).Return(store.Result)
+	ethTxMock.On("ResultsByStatus", mock.Anything, mock.Anything).Return(store.ResultsByStatus).Maybe()
+	ethTxMock.On("Remove", mock.Anything, mock.Anything).Return(store.Remove).Maybe()
 
-	return ethTxMock
+	return ethTxMock, store
+}
+
+// MonitoredTxID returns the ID the ethtxmanager gives to a monitored tx, the
+// hash of the tx without nonce nor fees
+func MonitoredTxID(to *common.Address, value *big.Int, data []byte, sidecar *types.BlobTxSidecar) common.Hash {
+	if sidecar == nil {
+		return types.NewTx(&types.LegacyTx{To: to, Value: value, Data: data}).Hash()
+	}
+	var blobTo common.Address
+	if to != nil {
+		blobTo = *to
+	}
+	return types.NewTx(&types.BlobTx{
+		To:         blobTo,
+		Value:      uint256.MustFromBig(value),
+		Data:       data,
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	}).Hash()
+}
+
+// buildMonitoredTx builds and signs an EIP-1559 tx, or an EIP-4844 one when
+// there is a sidecar, adding the gas offset to the estimated gas
+func buildMonitoredTx(
+	ctx context.Context,
+	client *simulated.Backend,
+	auth *bind.TransactOpts,
+	to *common.Address,
+	value *big.Int,
+	data []byte,
+	gasOffset uint64,
+	sidecar *types.BlobTxSidecar,
+) (*types.Transaction, error) {
+	if sidecar != nil && to == nil {
+		return nil, fmt.Errorf("blob tx without recipient")
+	}
+
+	ec := client.Client()
+	chainID, err := ec.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := ec.PendingNonceAt(ctx, auth.From)
+	if err != nil {
+		return nil, err
+	}
+	head, err := ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	gasTipCap, err := ec.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasFeeCap := new(big.Int).Add(gasTipCap, new(big.Int).Mul(head.BaseFee, big.NewInt(2))) //nolint:mnd
+
+	msg := ethereum.CallMsg{
+		From:      auth.From,
+		To:        to,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		Value:     value,
+		Data:      data,
+	}
+	var blobFeeCap *big.Int
+	if sidecar != nil {
+		var excessBlobGas uint64
+		if head.ExcessBlobGas != nil && head.BlobGasUsed != nil {
+			excessBlobGas = eip4844.CalcExcessBlobGas(*head.ExcessBlobGas, *head.BlobGasUsed)
+		}
+		blobFeeCap = new(big.Int).Mul(eip4844.CalcBlobFee(excessBlobGas), big.NewInt(feeMultiplier))
+		msg.BlobGasFeeCap = blobFeeCap
+		msg.BlobHashes = sidecar.BlobHashes()
+	}
+
+	gas, err := ec.EstimateGas(ctx, msg)
+	if err != nil {
+		// the call returns the revert reason of the failed estimation
+		if _, callErr := ec.CallContract(ctx, msg, nil); callErr != nil {
+			err = callErr
+		}
+		return nil, fmt.Errorf("failed to estimate gas: %w, data: %v", err, common.Bytes2Hex(data))
+	}
+	gas += gasOffset
+
+	var tx *types.Transaction
+	if sidecar == nil {
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Gas:       gas,
+			To:        to,
+			Value:     value,
+			Data:      data,
+		})
+	} else {
+		tx = types.NewTx(&types.BlobTx{
+			ChainID:    uint256.MustFromBig(chainID),
+			Nonce:      nonce,
+			GasTipCap:  uint256.MustFromBig(gasTipCap),
+			GasFeeCap:  uint256.MustFromBig(gasFeeCap),
+			Gas:        gas,
+			To:         *to,
+			Value:      uint256.MustFromBig(value),
+			Data:       data,
+			BlobFeeCap: uint256.MustFromBig(blobFeeCap),
+			BlobHashes: sidecar.BlobHashes(),
+			Sidecar:    sidecar,
+		})
+	}
+	return auth.Signer(auth.From, tx)
 }
@@ -0,0 +1,163 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	ethtxtypes "github.com/0xPolygon/zkevm-ethtx-manager/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEthTxManBackend(t *testing.T, alloc types.GenesisAlloc) (*simulated.Backend, *bind.TransactOpts) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(chainID))
+	require.NoError(t, err)
+	balance, ok := new(big.Int).SetString(defaultBalance, 10) //nolint:mnd
+	require.True(t, ok)
+
+	if alloc == nil {
+		alloc = types.GenesisAlloc{}
+	}
+	alloc[auth.From] = types.Account{Balance: balance}
+	client := simulated.NewBackend(alloc)
+	t.Cleanup(func() { require.NoError(t, client.Close()) })
+	client.Commit()
+	return client, auth
+}
+
+func TestEthTxManMockAdd(t *testing.T) {
+	ctx := context.Background()
+	client, auth := newEthTxManBackend(t, nil)
+	ethTxMan := NewEthTxManMock(t, client, auth)
+
+	to := common.HexToAddress("0x1234")
+	value := big.NewInt(1000)                               //nolint:mnd
+	id, err := ethTxMan.Add(ctx, &to, value, nil, 500, nil) //nolint:mnd
+	require.NoError(t, err)
+	assert.Equal(t, MonitoredTxID(&to, value, nil, nil), id)
+
+	balance, err := client.Client().BalanceAt(ctx, to, nil)
+	require.NoError(t, err)
+	assert.Equal(t, value, balance)
+
+	block, err := client.Client().BlockByNumber(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, block.Transactions(), 1)
+	tx := block.Transactions()[0]
+	assert.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+	assert.Equal(t, uint64(21500), tx.Gas()) //nolint:mnd
+
+	var blob kzg4844.Blob
+	commitment, err := kzg4844.BlobToCommitment(&blob)
+	require.NoError(t, err)
+	proof, err := kzg4844.ComputeBlobProof(&blob, commitment)
+	require.NoError(t, err)
+	sidecar := &types.BlobTxSidecar{
+		Blobs:       []kzg4844.Blob{blob},
+		Commitments: []kzg4844.Commitment{commitment},
+		Proofs:      []kzg4844.Proof{proof},
+	}
+	id, err = ethTxMan.Add(ctx, &to, nil, []byte{0x01}, 0, sidecar)
+	require.NoError(t, err)
+	assert.Equal(t, MonitoredTxID(&to, new(big.Int), []byte{0x01}, sidecar), id)
+	assert.NotEqual(t, MonitoredTxID(&to, new(big.Int), []byte{0x01}, nil), id)
+
+	block, err = client.Client().BlockByNumber(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, block.Transactions(), 1)
+	assert.Equal(t, uint8(types.BlobTxType), block.Transactions()[0].Type())
+	assert.Equal(t, sidecar.BlobHashes(), block.Transactions()[0].BlobHashes())
+
+	result, err := ethTxMan.Result(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, ethtxtypes.MonitoredTxStatusMined, result.Status)
+}
+
+// onceCode reverts when its first storage slot is set, and sets it
+var onceCode = common.FromHex("0x60005415600c5760006000fd5b600160005500")
+
+func TestMonitoredTxStore(t *testing.T) {
+	ctx := context.Background()
+	once := common.HexToAddress("0x0ce")
+	client, auth := newEthTxManBackend(t, types.GenesisAlloc{once: {Code: onceCode, Balance: new(big.Int)}})
+	ethTxMan, store := NewEthTxManMockWithStore(t, client, auth)
+
+	store.AutoCommit = false
+	first, err := ethTxMan.Add(ctx, &once, nil, []byte{0x01}, 0, nil)
+	require.NoError(t, err)
+	second, err := ethTxMan.Add(ctx, &once, nil, []byte{0x02}, 0, nil)
+	require.NoError(t, err)
+	_, err = ethTxMan.Add(ctx, &once, nil, []byte{0x01}, 0, nil)
+	require.ErrorIs(t, err, ethtxtypes.ErrAlreadyExists)
+
+	requireStatus := func(id common.Hash, expected ethtxtypes.MonitoredTxStatus) {
+		t.Helper()
+		result, err := ethTxMan.Result(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.Status)
+	}
+	requireStatus(first, ethtxtypes.MonitoredTxStatusSent)
+
+	// both txs pass the estimation but the second one reverts once mined
+	client.Commit()
+	requireStatus(first, ethtxtypes.MonitoredTxStatusMined)
+	requireStatus(second, ethtxtypes.MonitoredTxStatusFailed)
+	client.Commit()
+	requireStatus(first, ethtxtypes.MonitoredTxStatusSafe)
+	client.Commit()
+	requireStatus(first, ethtxtypes.MonitoredTxStatusFinalized)
+
+	results, err := ethTxMan.ResultsByStatus(ctx, []ethtxtypes.MonitoredTxStatus{ethtxtypes.MonitoredTxStatusFailed})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, second, results[0].ID)
+	require.Len(t, results[0].Txs, 1)
+	results, err = ethTxMan.ResultsByStatus(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, results, 2) //nolint:mnd
+
+	require.NoError(t, ethTxMan.Remove(ctx, second))
+	require.ErrorIs(t, ethTxMan.Remove(ctx, second), ethtxtypes.ErrNotFound)
+	_, err = ethTxMan.Result(ctx, second)
+	require.ErrorIs(t, err, ethtxtypes.ErrNotFound)
+
+	// injected failures
+	store.AutoCommit = true
+	errInjected := errors.New("injected")
+	store.AddHook = func(common.Hash) error { return errInjected }
+	_, err = ethTxMan.Add(ctx, &once, nil, []byte{0x03}, 0, nil)
+	require.ErrorIs(t, err, errInjected)
+	store.AddHook = nil
+
+	to := common.HexToAddress("0x1234")
+	store.SendHook = func(common.Hash) error { return errInjected }
+	id, err := ethTxMan.Add(ctx, &to, big.NewInt(1), nil, 0, nil)
+	require.NoError(t, err)
+	requireStatus(id, ethtxtypes.MonitoredTxStatusCreated)
+	require.ErrorIs(t, store.Send(ctx, id), errInjected)
+	results, err = ethTxMan.ResultsByStatus(ctx, []ethtxtypes.MonitoredTxStatus{ethtxtypes.MonitoredTxStatusCreated})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, id, results[0].ID)
+	// the created tx is sent again once the send succeeds
+	store.SendHook = nil
+	requireStatus(id, ethtxtypes.MonitoredTxStatusMined)
+	result, err := ethTxMan.Result(ctx, id)
+	require.NoError(t, err)
+	assert.Len(t, result.Txs, 1)
+
+	require.NoError(t, store.SetStatus(id, ethtxtypes.MonitoredTxStatusFailed))
+	requireStatus(id, ethtxtypes.MonitoredTxStatusFailed)
+}
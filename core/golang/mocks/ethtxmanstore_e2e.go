@@ -0,0 +1,288 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	ethtxtypes "github.com/0xPolygon/zkevm-ethtx-manager/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+const (
+	// DefaultSafeDepth is the number of blocks on top of the mined one for a
+	// monitored tx to be safe
+	DefaultSafeDepth = 1
+	// DefaultFinalizedDepth is the number of blocks on top of the mined one
+	// for a monitored tx to be finalized
+	DefaultFinalizedDepth = 2
+)
+
+// MonitoredTxStore keeps the monitored txs of the ethtxmanager mock and
+// computes their status from the simulated backend: created until sent, sent
+// until mined, then mined, safe and finalized as blocks are added on top, or
+// failed when the tx reverted. The created txs are sent again each time their
+// result is read, as the ethtxmanager retries them on each monitoring cycle.
+type MonitoredTxStore struct {
+	SafeDepth      uint64
+	FinalizedDepth uint64
+	// AutoCommit mines a block after sending each tx
+	AutoCommit bool
+
+	// AddHook is called before adding a monitored tx, an error is returned
+	// by Add and the tx is not added
+	AddHook func(id common.Hash) error
+	// SendHook is called before sending a monitored tx, an error leaves the
+	// tx created until the next Send, Result or ResultsByStatus
+	SendHook func(id common.Hash) error
+
+	client *simulated.Backend
+	auth   *bind.TransactOpts
+
+	mu  sync.Mutex
+	txs map[common.Hash]*monitoredTx
+	// ids keeps the adding order
+	ids []common.Hash
+}
+
+type monitoredTx struct {
+	to        *common.Address
+	value     *big.Int
+	data      []byte
+	gasOffset uint64
+	sidecar   *types.BlobTxSidecar
+
+	nonce   uint64
+	history []common.Hash
+	status  *ethtxtypes.MonitoredTxStatus
+}
+
+// NewMonitoredTxStore creates an empty store sending the txs with auth
+func NewMonitoredTxStore(client *simulated.Backend, auth *bind.TransactOpts) *MonitoredTxStore {
+	return &MonitoredTxStore{
+		SafeDepth:      DefaultSafeDepth,
+		FinalizedDepth: DefaultFinalizedDepth,
+		AutoCommit:     true,
+		client:         client,
+		auth:           auth,
+		txs:            map[common.Hash]*monitoredTx{},
+	}
+}
+
+// Add estimates the tx, adds it to the store and sends it
+func (s *MonitoredTxStore) Add(ctx context.Context, to *common.Address, value *big.Int, data []byte,
+	gasOffset uint64, sidecar *types.BlobTxSidecar) (common.Hash, error) {
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := MonitoredTxID(to, value, data, sidecar)
+	if _, found := s.txs[id]; found {
+		return common.Hash{}, ethtxtypes.ErrAlreadyExists
+	}
+	if s.AddHook != nil {
+		if err := s.AddHook(id); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	// the ethtxmanager rejects the txs it fails to estimate
+	if _, err := buildMonitoredTx(ctx, s.client, s.auth, to, value, data, gasOffset, sidecar); err != nil {
+		return common.Hash{}, err
+	}
+
+	s.txs[id] = &monitoredTx{to: to, value: value, data: data, gasOffset: gasOffset, sidecar: sidecar}
+	s.ids = append(s.ids, id)
+	// a send failure leaves the tx created, it is retried with the results
+	_ = s.send(ctx, id)
+	return id, nil
+}
+
+// Send sends a new tx for the monitored tx, e.g. to retry a created one
+func (s *MonitoredTxStore) Send(ctx context.Context, id common.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.txs[id]; !found {
+		return ethtxtypes.ErrNotFound
+	}
+	return s.send(ctx, id)
+}
+
+func (s *MonitoredTxStore) send(ctx context.Context, id common.Hash) error {
+	if s.SendHook != nil {
+		if err := s.SendHook(id); err != nil {
+			return err
+		}
+	}
+
+	mTx := s.txs[id]
+	tx, err := buildMonitoredTx(ctx, s.client, s.auth, mTx.to, mTx.value, mTx.data, mTx.gasOffset, mTx.sidecar)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Client().SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	mTx.nonce = tx.Nonce()
+	mTx.history = append(mTx.history, tx.Hash())
+	if s.AutoCommit {
+		s.client.Commit()
+	}
+	return nil
+}
+
+// SetStatus forces the status of the monitored tx, whatever its txs on the
+// backend, e.g. to fail a mined tx
+func (s *MonitoredTxStore) SetStatus(id common.Hash, status ethtxtypes.MonitoredTxStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mTx, found := s.txs[id]
+	if !found {
+		return ethtxtypes.ErrNotFound
+	}
+	mTx.status = &status
+	return nil
+}
+
+// Result returns the current result of the monitored tx
+func (s *MonitoredTxStore) Result(ctx context.Context, id common.Hash) (ethtxtypes.MonitoredTxResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.txs[id]; !found {
+		return ethtxtypes.MonitoredTxResult{}, ethtxtypes.ErrNotFound
+	}
+	s.retry(ctx, id)
+	return s.result(ctx, id)
+}
+
+// ResultsByStatus returns the results of the monitored txs with one of the
+// statuses, all of them if there is no status
+func (s *MonitoredTxStore) ResultsByStatus(ctx context.Context,
+	statuses []ethtxtypes.MonitoredTxStatus) ([]ethtxtypes.MonitoredTxResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]ethtxtypes.MonitoredTxResult, 0, len(s.ids))
+	for _, id := range s.ids {
+		s.retry(ctx, id)
+		result, err := s.result(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if len(statuses) == 0 || containsStatus(statuses, result.Status) {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// Remove removes the monitored tx from the store
+func (s *MonitoredTxStore) Remove(_ context.Context, id common.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.txs[id]; !found {
+		return ethtxtypes.ErrNotFound
+	}
+	delete(s.txs, id)
+	for i := range s.ids {
+		if s.ids[i] == id {
+			s.ids = append(s.ids[:i], s.ids[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// retry sends the monitored tx again if no tx was sent for it yet, a failure
+// leaves it created
+func (s *MonitoredTxStore) retry(ctx context.Context, id common.Hash) {
+	mTx := s.txs[id]
+	if len(mTx.history) > 0 || mTx.status != nil {
+		return
+	}
+	_ = s.send(ctx, id)
+}
+
+func (s *MonitoredTxStore) result(ctx context.Context, id common.Hash) (ethtxtypes.MonitoredTxResult, error) {
+	mTx := s.txs[id]
+	result := ethtxtypes.MonitoredTxResult{
+		ID:     id,
+		To:     mTx.to,
+		Nonce:  mTx.nonce,
+		Value:  mTx.value,
+		Data:   mTx.data,
+		Status: ethtxtypes.MonitoredTxStatusCreated,
+		Txs:    make(map[common.Hash]ethtxtypes.TxResult, len(mTx.history)),
+	}
+	if len(mTx.history) > 0 {
+		result.Status = ethtxtypes.MonitoredTxStatusSent
+	}
+
+	ec := s.client.Client()
+	for _, txHash := range mTx.history {
+		tx, pending, err := ec.TransactionByHash(ctx, txHash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return ethtxtypes.MonitoredTxResult{}, err
+		}
+		txResult := ethtxtypes.TxResult{Tx: tx}
+		if !pending {
+			if txResult.Receipt, err = ec.TransactionReceipt(ctx, txHash); err != nil {
+				return ethtxtypes.MonitoredTxResult{}, err
+			}
+			result.MinedAtBlockNumber = txResult.Receipt.BlockNumber
+			result.Status = ethtxtypes.MonitoredTxStatusFailed
+			if txResult.Receipt.Status == types.ReceiptStatusSuccessful {
+				if result.Status, err = s.minedStatus(ctx, txResult.Receipt.BlockNumber); err != nil {
+					return ethtxtypes.MonitoredTxResult{}, err
+				}
+			}
+		}
+		result.Txs[txHash] = txResult
+	}
+
+	if mTx.status != nil {
+		result.Status = *mTx.status
+	}
+	return result, nil
+}
+
+// minedStatus returns the status of a tx mined at the block given the blocks
+// on top of it
+func (s *MonitoredTxStore) minedStatus(ctx context.Context, minedAt *big.Int) (ethtxtypes.MonitoredTxStatus, error) {
+	latest, err := s.client.Client().BlockNumber(ctx)
+	if err != nil {
+		return "", err
+	}
+	depth := latest - minedAt.Uint64()
+	switch {
+	case depth >= s.FinalizedDepth:
+		return ethtxtypes.MonitoredTxStatusFinalized, nil
+	case depth >= s.SafeDepth:
+		return ethtxtypes.MonitoredTxStatusSafe, nil
+	default:
+		return ethtxtypes.MonitoredTxStatusMined, nil
+	}
+}
+
+func containsStatus(statuses []ethtxtypes.MonitoredTxStatus, status ethtxtypes.MonitoredTxStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
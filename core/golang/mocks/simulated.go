@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/0xPolygon/cdk-contracts-tooling/contracts/elderberry-paris/polygonzkevmbridgev2"
+	gerContractEVMChain "github.com/0xPolygon/cdk-contracts-tooling/contracts/manual/pessimisticglobalexitrootnopush0"
 	"github.com/0xPolygon/cdk/test/contracts/transparentupgradableproxy"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -21,6 +22,10 @@ const (
 	chainID              = 1337
 )
 
+// globalExitRootSetterRole is the role allowed to update the global exit
+// roots of the sovereign chain global exit root manager
+var globalExitRootSetterRole = common.HexToHash("0x7b95520991dfda409891be0afa2635b63540f92ee996fda0bf695a166e5c5176")
+
 type ClientRenamed simulated.Client
 
 type TestClient struct {
@@ -133,3 +138,28 @@ func SimulatedBackend(
 		EBZkevmBridgeProxyContract: ebZkevmBridgeProxyContract,
 	}
 }
+
+// DeployGERManager deploys the global exit root manager the bridge of the
+// setup points to, it must be called right after SimulatedBackend. The user
+// of the setup can update the global exit roots, e.g. to make claims valid.
+func DeployGERManager(
+	t *testing.T,
+	client *simulated.Backend,
+	setup *SimulatedBackendSetup,
+) (common.Address, *gerContractEVMChain.Pessimisticglobalexitrootnopush0) {
+	t.Helper()
+
+	gerAddr, _, gerContract, err := gerContractEVMChain.DeployPessimisticglobalexitrootnopush0(
+		setup.DeployerAuth, client.Client(), setup.UserAuth.From)
+	require.NoError(t, err)
+	client.Commit()
+
+	_, err = gerContract.GrantRole(setup.DeployerAuth, globalExitRootSetterRole, setup.UserAuth.From)
+	require.NoError(t, err)
+	client.Commit()
+
+	checkGERAddr, err := setup.EBZkevmBridgeProxyContract.GlobalExitRootManager(&bind.CallOpts{})
+	require.NoError(t, err)
+	require.Equal(t, checkGERAddr, gerAddr)
+	return gerAddr, gerContract
+}
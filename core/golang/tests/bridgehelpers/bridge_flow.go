@@ -0,0 +1,397 @@
+package bridgehelpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/cdk-contracts-tooling/contracts/elderberry-paris/polygonzkevmbridgev2"
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/agglayer/e2e/core/golang/tools/globalindex"
+	"github.com/agglayer/e2e/core/golang/tools/log"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// balanceOfABI is the fragment of the ERC20 ABI used to check the balances
+const balanceOfABI = `[{"type":"function","name":"balanceOf","stateMutability":"view",` +
+	`"inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}]`
+
+// erc20ABI is the parsed balanceOfABI
+var erc20ABI = mustParseABI(balanceOfABI)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Backend is the client of a network, both simulated.Client and
+// ethclient.Client implement it
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// Network is one side of a bridge route
+type Network struct {
+	Name          string
+	ID            uint32
+	Client        Backend
+	Bridge        *polygonzkevmbridgev2.Polygonzkevmbridgev2
+	BridgeService *bridgeservice.Client
+	// Auth sends the bridges from this network and the claims to it
+	Auth *bind.TransactOpts
+	// Commit mines a block, it must be set for simulated backends
+	Commit func()
+}
+
+// String returns the name and id of the network
+func (n *Network) String() string {
+	return fmt.Sprintf("%v (network %v)", n.Name, n.ID)
+}
+
+// commit mines a block if the network is simulated
+func (n *Network) commit() {
+	if n.Commit != nil {
+		n.Commit()
+	}
+}
+
+// waitMined waits for the tx receipt and checks its status
+func (n *Network) waitMined(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	n.commit()
+	receipt, err := bind.WaitMined(ctx, n.Client, tx)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("tx %v failed on %v", tx.Hash().String(), n)
+	}
+	return receipt, nil
+}
+
+// BridgeFlow bridges assets and messages from the origin network and claims
+// them on the destination one, as process_bridge_claim does in the bash
+// helpers. The same flow works for L1 to L2, L2 to L1 and L2 to L2 routes.
+type BridgeFlow struct {
+	Origin      *Network
+	Destination *Network
+	// Timeout applies to each of the waits of the flow
+	Timeout time.Duration
+}
+
+// NewBridgeFlow creates a flow between the networks
+func NewBridgeFlow(origin, destination *Network) *BridgeFlow {
+	return &BridgeFlow{Origin: origin, Destination: destination, Timeout: bridgeservice.TimeoutToBeIndexed}
+}
+
+// Deposit is a bridge sent on the origin network
+type Deposit struct {
+	TxHash common.Hash
+	Event  *polygonzkevmbridgev2.Polygonzkevmbridgev2BridgeEvent
+}
+
+// BridgeAssetParams are the params of a bridgeAsset call, a zero Token
+// bridges the gas token of the origin network
+type BridgeAssetParams struct {
+	DestinationAddress        common.Address
+	Amount                    *big.Int
+	Token                     common.Address
+	ForceUpdateGlobalExitRoot bool
+	PermitData                []byte
+}
+
+// BridgeAsset sends the asset to the destination network
+func (f *BridgeFlow) BridgeAsset(t *testing.T, ctx context.Context, params BridgeAssetParams) (*Deposit, error) {
+	auth := *f.Origin.Auth
+	auth.Context = ctx
+	if params.Token == (common.Address{}) {
+		auth.Value = params.Amount
+	}
+	log.Msgf(t, "bridging %v of token %v from %v to %v", params.Amount, params.Token.String(), f.Origin, f.Destination)
+
+	tx, err := f.Origin.Bridge.BridgeAsset(&auth, f.Destination.ID, params.DestinationAddress, params.Amount,
+		params.Token, params.ForceUpdateGlobalExitRoot, params.PermitData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bridge asset: %w", err)
+	}
+	return f.deposit(ctx, tx)
+}
+
+// BridgeMessage sends the message, and the value of the origin gas token,
+// to the destination network
+func (f *BridgeFlow) BridgeMessage(t *testing.T, ctx context.Context, destinationAddress common.Address, value *big.Int, forceUpdateGlobalExitRoot bool, metadata []byte) (*Deposit, error) {
+	auth := *f.Origin.Auth
+	auth.Context = ctx
+	auth.Value = value
+	log.Msgf(t, "bridging message %v with value %v from %v to %v", hexutil.Encode(metadata), value, f.Origin, f.Destination)
+
+	tx, err := f.Origin.Bridge.BridgeMessage(&auth, f.Destination.ID, destinationAddress, forceUpdateGlobalExitRoot, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bridge message: %w", err)
+	}
+	return f.deposit(ctx, tx)
+}
+
+// deposit waits for the bridge tx and decodes its BridgeEvent
+func (f *BridgeFlow) deposit(ctx context.Context, tx *types.Transaction) (*Deposit, error) {
+	receipt, err := f.Origin.waitMined(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range receipt.Logs {
+		event, err := f.Origin.Bridge.ParseBridgeEvent(*l)
+		if err == nil {
+			return &Deposit{TxHash: tx.Hash(), Event: event}, nil
+		}
+	}
+	return nil, fmt.Errorf("no BridgeEvent emitted by tx %v", tx.Hash().String())
+}
+
+// WaitIndexed waits until the origin bridge service indexes the deposit
+func (f *BridgeFlow) WaitIndexed(t *testing.T, ctx context.Context, deposit *Deposit) (*bridgeservice.Bridge, error) {
+	return bridgeservice.WaitBridge(t, ctx, f.Origin.BridgeService, f.Origin.ID, deposit.Event.DepositCount, f.Timeout)
+}
+
+// WaitL1InfoTreeLeaf waits until an L1 info tree leaf includes the bridge and
+// its global exit root is injected in the destination network
+func (f *BridgeFlow) WaitL1InfoTreeLeaf(t *testing.T, ctx context.Context, bridge *bridgeservice.Bridge) (*bridgeservice.L1InfoTreeLeaf, error) {
+	index, err := bridgeservice.WaitL1InfoTreeIndex(t, ctx, f.Origin.BridgeService, f.Origin.ID, bridge.DepositCount, f.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return bridgeservice.WaitInjectedL1InfoLeaf(t, ctx, f.Destination.BridgeService, f.Destination.ID, index, f.Timeout)
+}
+
+// ClaimParams are the params of the claimAsset or claimMessage call
+type ClaimParams struct {
+	LeafType               bridgeservice.LeafType
+	SmtProofLocalExitRoot  [32][32]byte
+	SmtProofRollupExitRoot [32][32]byte
+	GlobalIndex            *big.Int
+	MainnetExitRoot        [32]byte
+	RollupExitRoot         [32]byte
+	OriginNetwork          uint32
+	OriginAddress          common.Address
+	DestinationNetwork     uint32
+	DestinationAddress     common.Address
+	Amount                 *big.Int
+	Metadata               []byte
+}
+
+// BuildClaimParams waits for the proof of the bridge against the leaf and
+// returns the params to claim it
+func (f *BridgeFlow) BuildClaimParams(t *testing.T, ctx context.Context, bridge *bridgeservice.Bridge, leaf *bridgeservice.L1InfoTreeLeaf) (*ClaimParams, error) {
+	proof, err := bridgeservice.WaitClaimProof(t, ctx, f.Origin.BridgeService, f.Origin.ID, bridge.DepositCount, leaf.L1InfoTreeIndex, f.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &ClaimParams{
+		LeafType:           bridge.LeafType,
+		GlobalIndex:        globalindex.New(f.Origin.ID, bridge.DepositCount).Big(),
+		MainnetExitRoot:    proof.L1InfoTreeLeaf.MainnetExitRoot,
+		RollupExitRoot:     proof.L1InfoTreeLeaf.RollupExitRoot,
+		OriginNetwork:      bridge.OriginNetwork,
+		OriginAddress:      bridge.OriginAddress,
+		DestinationNetwork: bridge.DestinationNetwork,
+		DestinationAddress: bridge.DestinationAddress,
+		Amount:             new(big.Int),
+		Metadata:           bridge.Metadata,
+	}
+	if bridge.Amount != nil && bridge.Amount.Int != nil {
+		params.Amount.Set(bridge.Amount.Int)
+	}
+	for i := range proof.ProofLocalExitRoot {
+		params.SmtProofLocalExitRoot[i] = proof.ProofLocalExitRoot[i]
+		params.SmtProofRollupExitRoot[i] = proof.ProofRollupExitRoot[i]
+	}
+	return params, nil
+}
+
+// Claim submits the claim on the destination network. The claim is retried
+// while the global exit root is not injected yet.
+func (f *BridgeFlow) Claim(t *testing.T, ctx context.Context, params *ClaimParams) (*types.Receipt, error) {
+	bridgeABI, err := polygonzkevmbridgev2.Polygonzkevmbridgev2MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	var receipt *types.Receipt
+	description := fmt.Sprintf("claim of global index %v on %v", params.GlobalIndex, f.Destination)
+	err = engine.WaitFor(t, ctx, description, engine.Every(bridgeservice.PollInterval), f.Timeout, func(ctx context.Context) (bool, error) {
+		auth := *f.Destination.Auth
+		auth.Context = ctx
+
+		var tx *types.Transaction
+		var err error
+		if params.LeafType == bridgeservice.LeafTypeAsset {
+			tx, err = f.Destination.Bridge.ClaimAsset(&auth, params.SmtProofLocalExitRoot, params.SmtProofRollupExitRoot,
+				params.GlobalIndex, params.MainnetExitRoot, params.RollupExitRoot, params.OriginNetwork, params.OriginAddress,
+				params.DestinationNetwork, params.DestinationAddress, params.Amount, params.Metadata)
+		} else {
+			tx, err = f.Destination.Bridge.ClaimMessage(&auth, params.SmtProofLocalExitRoot, params.SmtProofRollupExitRoot,
+				params.GlobalIndex, params.MainnetExitRoot, params.RollupExitRoot, params.OriginNetwork, params.OriginAddress,
+				params.DestinationNetwork, params.DestinationAddress, params.Amount, params.Metadata)
+		}
+		if err != nil {
+			if revert, decodeErr := decodeRevert(err, bridgeABI); decodeErr == nil {
+				if revert.Name == "GlobalExitRootInvalid" {
+					return false, engine.NotReady("global exit root not injected yet")
+				}
+				return false, fmt.Errorf("claim reverted with %v", revert)
+			}
+			return false, err
+		}
+
+		receipt, err = f.Destination.waitMined(ctx, tx)
+		return err == nil, err
+	})
+	return receipt, err
+}
+
+// decodeRevert decodes the revert data carried by an error of the go-ethereum
+// clients
+func decodeRevert(err error, abis ...*abi.ABI) (*engine.Revert, error) {
+	var dataErr interface{ ErrorData() interface{} }
+	if !errors.As(err, &dataErr) {
+		return nil, engine.ErrNoRevertData
+	}
+	data, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, engine.ErrNoRevertData
+	}
+	decoded, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return engine.DecodeRevert(decoded, abis...)
+}
+
+// Result is the outcome of a full bridge and claim flow
+type Result struct {
+	Deposit        *Deposit
+	Bridge         *bridgeservice.Bridge
+	L1InfoTreeLeaf *bridgeservice.L1InfoTreeLeaf
+	ClaimParams    *ClaimParams
+	ClaimReceipt   *types.Receipt
+	// Token is the token received on the destination network, zero for the
+	// gas token
+	Token common.Address
+	// BalanceBefore and BalanceAfter are the balances of the destination
+	// address around the claim
+	BalanceBefore *big.Int
+	BalanceAfter  *big.Int
+}
+
+// Run bridges the asset, claims it on the destination network and checks
+// the balance of the destination address increased by the amount. The gas
+// token of both networks is assumed to be the same when Token is zero.
+func (f *BridgeFlow) Run(t *testing.T, ctx context.Context, params BridgeAssetParams) (*Result, error) {
+	deposit, err := f.BridgeAsset(t, ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Deposit: deposit}
+
+	if result.Bridge, err = f.WaitIndexed(t, ctx, deposit); err != nil {
+		return result, err
+	}
+	if result.L1InfoTreeLeaf, err = f.WaitL1InfoTreeLeaf(t, ctx, result.Bridge); err != nil {
+		return result, err
+	}
+	if result.ClaimParams, err = f.BuildClaimParams(t, ctx, result.Bridge, result.L1InfoTreeLeaf); err != nil {
+		return result, err
+	}
+
+	// the wrapped token is deployed by the first claim of the origin token
+	wrapped := result.Bridge.OriginAddress != (common.Address{}) && result.Bridge.OriginNetwork != f.Destination.ID
+	if result.Token, err = f.destinationToken(ctx, result.Bridge); err != nil {
+		return result, err
+	}
+	result.BalanceBefore = new(big.Int)
+	if !wrapped || result.Token != (common.Address{}) {
+		if result.BalanceBefore, err = f.balance(ctx, result.Token, params.DestinationAddress); err != nil {
+			return result, err
+		}
+	}
+
+	if result.ClaimReceipt, err = f.Claim(t, ctx, result.ClaimParams); err != nil {
+		return result, err
+	}
+
+	if wrapped && result.Token == (common.Address{}) {
+		if result.Token, err = f.destinationToken(ctx, result.Bridge); err != nil {
+			return result, err
+		}
+	}
+	if result.BalanceAfter, err = f.balance(ctx, result.Token, params.DestinationAddress); err != nil {
+		return result, err
+	}
+
+	expected := new(big.Int).Add(result.BalanceBefore, result.ClaimParams.Amount)
+	if result.Token == (common.Address{}) && params.DestinationAddress == f.Destination.Auth.From {
+		gasCost := new(big.Int).Mul(new(big.Int).SetUint64(result.ClaimReceipt.GasUsed), result.ClaimReceipt.EffectiveGasPrice)
+		expected.Sub(expected, gasCost)
+	}
+	if result.BalanceAfter.Cmp(expected) != 0 {
+		return result, fmt.Errorf("unexpected balance of %v on %v: %v, expected %v",
+			params.DestinationAddress.String(), f.Destination, result.BalanceAfter, expected)
+	}
+	log.Msgf(t, "claimed %v on %v, balance of %v: %v", result.ClaimParams.Amount, f.Destination, params.DestinationAddress.String(), result.BalanceAfter)
+	return result, nil
+}
+
+// destinationToken returns the token the bridge is paid with on the
+// destination network: the origin token if it is native to the destination,
+// the gas token for the gas token of the origin or the wrapped token, which
+// is zero until the first claim deploys it
+func (f *BridgeFlow) destinationToken(ctx context.Context, bridge *bridgeservice.Bridge) (common.Address, error) {
+	switch {
+	case bridge.OriginNetwork == f.Destination.ID:
+		return bridge.OriginAddress, nil
+	case bridge.OriginAddress == (common.Address{}):
+		return common.Address{}, nil
+	default:
+		return f.Destination.Bridge.GetTokenWrappedAddress(&bind.CallOpts{Context: ctx}, bridge.OriginNetwork, bridge.OriginAddress)
+	}
+}
+
+// balance returns the balance of the account on the destination network,
+// a zero token returns the gas token balance
+func (f *BridgeFlow) balance(ctx context.Context, token, account common.Address) (*big.Int, error) {
+	if token == (common.Address{}) {
+		return f.Destination.Client.BalanceAt(ctx, account, nil)
+	}
+
+	input, err := erc20ABI.Pack("balanceOf", account)
+	if err != nil {
+		return nil, err
+	}
+	output, err := f.Destination.Client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: input}, nil)
+	if err != nil {
+		return nil, err
+	}
+	values, err := erc20ABI.Unpack("balanceOf", output)
+	if err != nil {
+		return nil, err
+	}
+	balance, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected balanceOf output %v", values)
+	}
+	return balance, nil
+}
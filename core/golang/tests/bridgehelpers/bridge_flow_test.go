@@ -0,0 +1,116 @@
+package bridgehelpers
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/cdk-contracts-tooling/contracts/elderberry-paris/polygonzkevmbridgev2"
+	"github.com/agglayer/e2e/core/golang/mocks"
+	"github.com/agglayer/e2e/core/golang/mocks/bridgeservicemock"
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// injectingBackend runs inject the first time a gas estimation fails, e.g. to
+// inject the global exit root once a claim reverted
+type injectingBackend struct {
+	Backend
+	inject   func()
+	failures int
+}
+
+func (b *injectingBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	gas, err := b.Backend.EstimateGas(ctx, msg)
+	if err != nil {
+		b.failures++
+		if b.failures == 1 {
+			b.inject()
+		}
+	}
+	return gas, err
+}
+
+// newSimulatedNetwork starts a simulated network with its bridge and bridge
+// service
+func newSimulatedNetwork(t *testing.T, name string, networkID uint32) (*Network, *simulated.Backend, *mocks.SimulatedBackendSetup, *bridgeservicemock.Server) {
+	t.Helper()
+
+	client, setup := mocks.SimulatedBackend(t, nil, networkID)
+	service := bridgeservicemock.New(t, client.Client(), setup.EBZkevmBridgeProxyAddr, networkID)
+	network := &Network{
+		Name:          name,
+		ID:            networkID,
+		Client:        client.Client(),
+		Bridge:        setup.EBZkevmBridgeProxyContract,
+		BridgeService: bridgeservice.NewClient(service.URL()),
+		Auth:          setup.UserAuth,
+		Commit:        func() { client.Commit() },
+	}
+	return network, client, setup, service
+}
+
+func TestBridgeFlow(t *testing.T) {
+	ctx := context.Background()
+	previous := bridgeservice.PollInterval
+	bridgeservice.PollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { bridgeservice.PollInterval = previous })
+
+	origin, _, _, originService := newSimulatedNetwork(t, "L1", 0)
+	destination, destinationClient, destinationSetup, destinationService := newSimulatedNetwork(t, "L2", 1)
+	_, gerContract := mocks.DeployGERManager(t, destinationClient, destinationSetup)
+
+	flow := NewBridgeFlow(origin, destination)
+	flow.Timeout = 5 * time.Second
+
+	receiver := common.HexToAddress("0xde57")
+	amount := big.NewInt(1000) //nolint:mnd
+	deposit, err := flow.BridgeAsset(t, ctx, BridgeAssetParams{DestinationAddress: receiver, Amount: amount})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), deposit.Event.DepositCount)
+
+	bridge, err := flow.WaitIndexed(t, ctx, deposit)
+	require.NoError(t, err)
+	assert.Equal(t, deposit.TxHash, bridge.TxHash)
+	assert.Equal(t, amount.String(), bridge.Amount.String())
+
+	// the aggoracle would add the leaf on L1 and inject it in L2
+	leaf, err := originService.AddL1InfoTreeLeaf(ctx, bridgeservice.L1InfoTreeLeaf{Timestamp: 1})
+	require.NoError(t, err)
+	_, err = destinationService.AddL1InfoTreeLeaf(ctx, bridgeservice.L1InfoTreeLeaf{MainnetExitRoot: leaf.MainnetExitRoot, Timestamp: 1})
+	require.NoError(t, err)
+	require.NoError(t, destinationService.InjectL1InfoTreeLeaf(leaf.L1InfoTreeIndex))
+
+	injected, err := flow.WaitL1InfoTreeLeaf(t, ctx, bridge)
+	require.NoError(t, err)
+	assert.Equal(t, leaf.GlobalExitRoot, injected.GlobalExitRoot)
+	params, err := flow.BuildClaimParams(t, ctx, bridge, injected)
+	require.NoError(t, err)
+
+	// the global exit root reaches the bridge only after the first claim
+	// reverted with GlobalExitRootInvalid
+	backend := &injectingBackend{Backend: destination.Client, inject: func() {
+		_, err := gerContract.UpdateGlobalExitRoot(destinationSetup.UserAuth, leaf.GlobalExitRoot)
+		require.NoError(t, err)
+		destinationClient.Commit()
+	}}
+	destination.Client = backend
+	destination.Bridge, err = polygonzkevmbridgev2.NewPolygonzkevmbridgev2(destinationSetup.EBZkevmBridgeProxyAddr, backend)
+	require.NoError(t, err)
+
+	balanceBefore, err := flow.balance(ctx, common.Address{}, receiver)
+	require.NoError(t, err)
+	receipt, err := flow.Claim(t, ctx, params)
+	require.NoError(t, err)
+	assert.Equal(t, 1, backend.failures)
+	assert.NotNil(t, receipt)
+
+	balanceAfter, err := flow.balance(ctx, common.Address{}, receiver)
+	require.NoError(t, err)
+	assert.Equal(t, new(big.Int).Add(balanceBefore, amount).String(), balanceAfter.String())
+}
@@ -0,0 +1,131 @@
+package gerhelpers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	gerContractL1 "github.com/0xPolygon/cdk-contracts-tooling/contracts/manual/globalexitrootnopush0"
+	gerContractEVMChain "github.com/0xPolygon/cdk-contracts-tooling/contracts/manual/pessimisticglobalexitrootnopush0"
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// TimeoutGERToBeInjected is the usual time for the aggoracle to inject an L1
+// global exit root in a sovereign chain
+const TimeoutGERToBeInjected = 5 * time.Minute
+
+// GERPollInterval is the time between two checks of the injection waits
+var GERPollInterval = 2 * time.Second
+
+// ExitRoots is a global exit root and the exit roots it is made of
+type ExitRoots struct {
+	MainnetExitRoot common.Hash
+	RollupExitRoot  common.Hash
+	GlobalExitRoot  common.Hash
+}
+
+// ComputeGER returns keccak(mainnetExitRoot, rollupExitRoot)
+func ComputeGER(mainnetExitRoot, rollupExitRoot common.Hash) common.Hash {
+	return merkletree.GlobalExitRoot(mainnetExitRoot, rollupExitRoot)
+}
+
+// Verify checks the global exit root matches the exit roots
+func (r *ExitRoots) Verify() error {
+	computed := ComputeGER(r.MainnetExitRoot, r.RollupExitRoot)
+	if computed != r.GlobalExitRoot {
+		return fmt.Errorf("global exit root %v doesn't match mainnet exit root %v and rollup exit root %v, expected %v",
+			r.GlobalExitRoot.String(), r.MainnetExitRoot.String(), r.RollupExitRoot.String(), computed.String())
+	}
+	return nil
+}
+
+// L1ExitRoots returns the last exit roots of the L1 global exit root contract
+// and checks they are consistent. Set the block number of the opts to read
+// the three roots from the same block of a live network.
+func L1ExitRoots(opts *bind.CallOpts, contract *gerContractL1.Globalexitrootnopush0) (*ExitRoots, error) {
+	mainnetExitRoot, err := contract.LastMainnetExitRoot(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last mainnet exit root: %w", err)
+	}
+	rollupExitRoot, err := contract.LastRollupExitRoot(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last rollup exit root: %w", err)
+	}
+	globalExitRoot, err := contract.GetLastGlobalExitRoot(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last global exit root: %w", err)
+	}
+
+	roots := &ExitRoots{
+		MainnetExitRoot: mainnetExitRoot,
+		RollupExitRoot:  rollupExitRoot,
+		GlobalExitRoot:  globalExitRoot,
+	}
+	return roots, roots.Verify()
+}
+
+// GERTimestamp returns the timestamp the global exit root was registered at
+// in the L1 contract, zero if it is unknown
+func GERTimestamp(opts *bind.CallOpts, contract *gerContractL1.Globalexitrootnopush0, ger common.Hash) (*big.Int, error) {
+	return contract.GlobalExitRootMap(opts, ger)
+}
+
+// IsGERInjected reports whether the global exit root is injected in the
+// sovereign chain contract
+func IsGERInjected(opts *bind.CallOpts, contract *gerContractEVMChain.Pessimisticglobalexitrootnopush0, ger common.Hash) (bool, error) {
+	timestamp, err := contract.GlobalExitRootMap(opts, ger)
+	if err != nil {
+		return false, fmt.Errorf("failed to get timestamp of global exit root %v: %w", ger.String(), err)
+	}
+	return timestamp.Sign() != 0, nil
+}
+
+// WaitGERInjected waits until the global exit root is injected in the
+// sovereign chain contract
+func WaitGERInjected(t *testing.T, ctx context.Context, contract *gerContractEVMChain.Pessimisticglobalexitrootnopush0, ger common.Hash, timeout time.Duration) error {
+	description := fmt.Sprintf("global exit root %v to be injected", ger.String())
+	return engine.WaitFor(t, ctx, description, engine.Every(GERPollInterval), timeout, func(ctx context.Context) (bool, error) {
+		injected, err := IsGERInjected(&bind.CallOpts{Context: ctx}, contract, ger)
+		if err != nil {
+			return false, err
+		}
+		if !injected {
+			return false, engine.NotReady("not injected")
+		}
+		return true, nil
+	})
+}
+
+// WaitL1GERInjected waits until the last global exit root of L1 is injected
+// in the sovereign chain and returns its exit roots
+func WaitL1GERInjected(t *testing.T, ctx context.Context, l1Contract *gerContractL1.Globalexitrootnopush0,
+	l2Contract *gerContractEVMChain.Pessimisticglobalexitrootnopush0, timeout time.Duration) (*ExitRoots, error) {
+	roots, err := L1ExitRoots(&bind.CallOpts{Context: ctx}, l1Contract)
+	if err != nil {
+		return nil, err
+	}
+	return roots, WaitGERInjected(t, ctx, l2Contract, roots.GlobalExitRoot, timeout)
+}
+
+// RequireGERInjected fails the test if the global exit root isn't injected
+// in the sovereign chain before the timeout
+func RequireGERInjected(t *testing.T, ctx context.Context, contract *gerContractEVMChain.Pessimisticglobalexitrootnopush0, ger common.Hash, timeout time.Duration) {
+	t.Helper()
+	require.NoError(t, WaitGERInjected(t, ctx, contract, ger, timeout))
+}
+
+// RequireL1GERInjected fails the test if the last global exit root of L1 is
+// inconsistent or isn't injected in the sovereign chain before the timeout
+func RequireL1GERInjected(t *testing.T, ctx context.Context, l1Contract *gerContractL1.Globalexitrootnopush0,
+	l2Contract *gerContractEVMChain.Pessimisticglobalexitrootnopush0, timeout time.Duration) *ExitRoots {
+	t.Helper()
+	roots, err := WaitL1GERInjected(t, ctx, l1Contract, l2Contract, timeout)
+	require.NoError(t, err)
+	return roots
+}
@@ -0,0 +1,55 @@
+package gerhelpers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/mocks"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitRoots(t *testing.T) {
+	mainnetExitRoot := common.HexToHash("0x1")
+	rollupExitRoot := common.HexToHash("0x2")
+	ger := ComputeGER(mainnetExitRoot, rollupExitRoot)
+	assert.Equal(t, merkletree.GlobalExitRoot(mainnetExitRoot, rollupExitRoot), ger)
+	assert.Equal(t, crypto.Keccak256Hash(mainnetExitRoot.Bytes(), rollupExitRoot.Bytes()), ger)
+
+	roots := &ExitRoots{MainnetExitRoot: mainnetExitRoot, RollupExitRoot: rollupExitRoot, GlobalExitRoot: ger}
+	require.NoError(t, roots.Verify())
+
+	// the order of the exit roots matters
+	roots.GlobalExitRoot = ComputeGER(rollupExitRoot, mainnetExitRoot)
+	require.ErrorContains(t, roots.Verify(), "doesn't match")
+}
+
+func TestIsGERInjected(t *testing.T) {
+	ctx := context.Background()
+	previous := GERPollInterval
+	GERPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { GERPollInterval = previous })
+
+	client, setup := mocks.SimulatedBackend(t, nil, 1)
+	_, gerContract := mocks.DeployGERManager(t, client, setup)
+	ger := ComputeGER(common.HexToHash("0x1"), common.HexToHash("0x2"))
+
+	injected, err := IsGERInjected(&bind.CallOpts{Context: ctx}, gerContract, ger)
+	require.NoError(t, err)
+	assert.False(t, injected)
+	require.Error(t, WaitGERInjected(t, ctx, gerContract, ger, 50*time.Millisecond))
+
+	_, err = gerContract.UpdateGlobalExitRoot(setup.UserAuth, ger)
+	require.NoError(t, err)
+	client.Commit()
+
+	injected, err = IsGERInjected(&bind.CallOpts{Context: ctx}, gerContract, ger)
+	require.NoError(t, err)
+	assert.True(t, injected)
+	require.NoError(t, WaitGERInjected(t, ctx, gerContract, ger, time.Second))
+}
@@ -2,7 +2,6 @@ package test
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"math/big"
 	"os"
@@ -11,8 +10,8 @@ import (
 
 	"github.com/agglayer/e2e/core/golang/contracts/zkcounters"
 	"github.com/agglayer/e2e/core/golang/tools/engine"
-	"github.com/agglayer/e2e/core/golang/tools/hex"
 	"github.com/agglayer/e2e/core/golang/tools/log"
+	"github.com/agglayer/e2e/core/golang/tools/zkevm"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -36,17 +35,10 @@ func TestZkCounters(t *testing.T) {
 	ctx := context.Background()
 	client := engine.MustGetClient(rpcURL)
 
-	forkIdResponse, err := engine.RPCCall(t, rpcURL, engine.NewRequest("zkevm_getForkId"))
+	zkevmClient := zkevm.NewClient(rpcURL)
+	forkId, err := zkevmClient.ForkID(t, ctx)
 	require.NoError(t, err)
-	require.Nil(t, forkIdResponse.Error)
-	require.NotNil(t, forkIdResponse.Result)
-
-	var forkIdHex string
-	err = json.Unmarshal(forkIdResponse.Result, &forkIdHex)
-	require.NoError(t, err)
-	require.NotEmpty(t, forkIdHex)
-
-	forkId := hex.DecodeUint64(forkIdHex)
+	require.NotZero(t, forkId)
 
 	blockNumber, err := client.BlockNumber(ctx)
 	require.NoError(t, err)
@@ -67,35 +59,21 @@ func TestZkCounters(t *testing.T) {
 
 	// THIS COMMENTED CODE BLOCK IS WAITING ERIGON TO FIX ESTIMATE COUNTERS
 	{
-		// estimateCounters := func(args map[string]any, counter string) (uint64, uint64, string) {
-		// 	req := engine.NewRequest("zkevm_estimateCounters", args)
-		// 	res, err := engine.RPCCall(t, rpcURL, req)
+		// estimateCounters := func(args zkevm.TxArgs, counter string) (uint64, uint64, string) {
+		// 	res, err := zkevmClient.EstimateCounters(t, ctx, args)
 		// 	require.NoError(t, err)
-		// 	require.Nil(t, res.Error)
-		// 	require.NotNil(t, res.Result)
-
-		// 	resMap := map[string]any{}
-		// 	err = json.Unmarshal(res.Result, &resMap)
-		// 	require.NoError(t, err)
-
-		// 	countersUsedKey := "countersUsed"
-		// 	countersLimitsKey := "countersLimits"
+		// 	require.NotNil(t, res)
 
 		// 	usedCounterKey := counter
 		// 	maxCounterKey := counter
 
-		// 	countersUsed := resMap[countersUsedKey].(map[string]any)
-		// 	countersLimits := resMap[countersLimitsKey].(map[string]any)
-		// 	oocError := ""
-		// 	if v, found := resMap["oocError"]; found {
-		// 		oocError = v.(string)
-		// 	}
-
-		// 	if v, found := resMap["revertInfo"]; found {
-		// 		if v, found := v.(map[string]any)["message"]; found {
-		// 			oocError = v.(string)
-		// 		}
+		// 	countersUsed := map[string]uint64{}
+		// 	countersLimits := map[string]uint64{}
+		// 	for _, k := range []string{"gas", "keccakHashes", "poseidonHashes", "poseidonPaddings", "memAligns", "arithmetics", "binaries", "steps", "SHA256hashes"} {
+		// 		countersUsed[k], _ = res.CountersUsed.Get(k)
+		// 		countersLimits[k], _ = res.CountersLimits.Get(k)
 		// 	}
+		// 	oocError := res.FailureReason()
 
 		// 	// print counters
 		// 	const logColumnWidth = 30
@@ -130,8 +108,8 @@ func TestZkCounters(t *testing.T) {
 		// 	}
 		// 	log.Msg(t, columnSeparator, headerSeparator, columnSeparator, headerSeparator, columnSeparator, headerSeparator, columnSeparator)
 
-		// 	used := uint64(countersUsed[usedCounterKey].(float64))
-		// 	max := uint64(countersLimits[maxCounterKey].(float64))
+		// 	used, _ := res.CountersUsed.Get(usedCounterKey)
+		// 	max, _ := res.CountersLimits.Get(maxCounterKey)
 		// 	return used, max, oocError
 		// }
 	}
@@ -371,12 +349,8 @@ func TestZkCounters(t *testing.T) {
 
 			// wait for tx
 			if txMustGetMined {
-				err = engine.WaitTxToBeMined(t, ctx, rpcURL, tx.Hash(), engine.TimeoutTxToBeMined)
-				require.NoError(t, err)
-
-				receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+				_, err = engine.WaitSuccessfulReceipt(t, ctx, rpcURL, tx.Hash(), engine.TimeoutTxToBeMined)
 				require.NoError(t, err)
-				require.Equal(t, uint64(types.ReceiptStatusSuccessful), receipt.Status)
 			} else {
 				err = engine.WaitTxToDisappearByHash(t, ctx, rpcURL, tx.Hash(), engine.TimeoutTxToDisappear)
 				if err != nil && strings.Contains(err.Error(), "was mined and will never disappear") {
@@ -389,7 +363,7 @@ func TestZkCounters(t *testing.T) {
 			// THIS COMMENTED CODE BLOCK IS WAITING ERIGON TO FIX ESTIMATE COUNTERS
 			{
 				// // estimate counters
-				// args := engine.TxToTxArgs(*tx)
+				// args := zkevm.TxToArgs(tx, tcAuth.From)
 				// used, max, counterError := estimateCounters(args, testCase.counter)
 
 				// // check target counter against limit
@@ -0,0 +1,158 @@
+package agglayer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Client is a typed client for the interop JSON RPC namespace exposed by the
+// agglayer read RPC
+type Client struct {
+	rpc *engine.Client
+}
+
+// NewClient creates an interop client for the provided URL
+func NewClient(url string, opts ...engine.ClientOption) *Client {
+	return &Client{rpc: engine.NewClient(url, opts...)}
+}
+
+// NewClientFrom creates an interop client on top of an existing JSON RPC client
+func NewClientFrom(rpc *engine.Client) *Client {
+	return &Client{rpc: rpc}
+}
+
+// RPC returns the underlying JSON RPC client
+func (c *Client) RPC() *engine.Client {
+	return c.rpc
+}
+
+// CertificateHeader returns the header of the certificate
+func (c *Client) CertificateHeader(t *testing.T, ctx context.Context, certificateID CertificateID) (*CertificateHeader, error) {
+	var result *CertificateHeader
+	err := c.rpc.CallResult(t, ctx, &result, "interop_getCertificateHeader", certificateID)
+	return result, err
+}
+
+// LatestKnownCertificateHeader returns the header of the latest certificate
+// received for the network, or nil if there is none
+func (c *Client) LatestKnownCertificateHeader(t *testing.T, ctx context.Context, networkID NetworkID) (*CertificateHeader, error) {
+	var result *CertificateHeader
+	err := c.rpc.CallResult(t, ctx, &result, "interop_getLatestKnownCertificateHeader", networkID)
+	return result, err
+}
+
+// LatestPendingCertificateHeader returns the header of the latest certificate
+// of the network not settled yet, or nil if there is none
+func (c *Client) LatestPendingCertificateHeader(t *testing.T, ctx context.Context, networkID NetworkID) (*CertificateHeader, error) {
+	var result *CertificateHeader
+	err := c.rpc.CallResult(t, ctx, &result, "interop_getLatestPendingCertificateHeader", networkID)
+	return result, err
+}
+
+// LatestSettledCertificateHeader returns the header of the latest certificate
+// of the network settled on L1, or nil if there is none
+func (c *Client) LatestSettledCertificateHeader(t *testing.T, ctx context.Context, networkID NetworkID) (*CertificateHeader, error) {
+	var result *CertificateHeader
+	err := c.rpc.CallResult(t, ctx, &result, "interop_getLatestSettledCertificateHeader", networkID)
+	return result, err
+}
+
+// EpochConfiguration returns the epoch configuration of the agglayer
+func (c *Client) EpochConfiguration(t *testing.T, ctx context.Context) (*EpochConfiguration, error) {
+	var result *EpochConfiguration
+	err := c.rpc.CallResult(t, ctx, &result, "interop_getEpochConfiguration")
+	return result, err
+}
+
+// TxStatus returns the status of a settlement tx
+func (c *Client) TxStatus(t *testing.T, ctx context.Context, txHash common.Hash) (TxStatus, error) {
+	var result TxStatus
+	err := c.rpc.CallResult(t, ctx, &result, "interop_getTxStatus", txHash)
+	return result, err
+}
+
+// SendCertificate submits the certificate and returns its id
+func (c *Client) SendCertificate(t *testing.T, ctx context.Context, certificate *Certificate) (CertificateID, error) {
+	var result CertificateID
+	err := c.rpc.CallResult(t, ctx, &result, "interop_sendCertificate", certificate)
+	return result, err
+}
+
+// AdminClient is a typed client for the admin JSON RPC namespace, which the
+// agglayer exposes on its own port
+type AdminClient struct {
+	rpc *engine.Client
+}
+
+// NewAdminClient creates an admin client for the provided URL
+func NewAdminClient(url string, opts ...engine.ClientOption) *AdminClient {
+	return &AdminClient{rpc: engine.NewClient(url, opts...)}
+}
+
+// NewAdminClientFrom creates an admin client on top of an existing JSON RPC client
+func NewAdminClientFrom(rpc *engine.Client) *AdminClient {
+	return &AdminClient{rpc: rpc}
+}
+
+// RPC returns the underlying JSON RPC client
+func (c *AdminClient) RPC() *engine.Client {
+	return c.rpc
+}
+
+// GetCertificate returns the certificate and its header, the header is nil
+// when the agglayer has no header stored for the certificate
+func (c *AdminClient) GetCertificate(t *testing.T, ctx context.Context, certificateID CertificateID) (*Certificate, *CertificateHeader, error) {
+	var result []json.RawMessage
+	err := c.rpc.CallResult(t, ctx, &result, "admin_getCertificate", certificateID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(result) != 2 { //nolint:mnd
+		return nil, nil, fmt.Errorf("admin_getCertificate returned %v items, expected 2", len(result))
+	}
+
+	var certificate *Certificate
+	if err := json.Unmarshal(result[0], &certificate); err != nil {
+		return nil, nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	var header *CertificateHeader
+	if err := json.Unmarshal(result[1], &header); err != nil {
+		return nil, nil, fmt.Errorf("invalid certificate header: %w", err)
+	}
+	return certificate, header, nil
+}
+
+// SetLatestPendingCertificate marks the certificate as the latest pending one
+// of its network
+func (c *AdminClient) SetLatestPendingCertificate(t *testing.T, ctx context.Context, certificateID CertificateID) error {
+	return c.rpc.CallResult(t, ctx, nil, "admin_setLatestPendingCertificate", certificateID)
+}
+
+// SetLatestProvenCertificate marks the certificate as the latest proven one
+// of its network
+func (c *AdminClient) SetLatestProvenCertificate(t *testing.T, ctx context.Context, certificateID CertificateID) error {
+	return c.rpc.CallResult(t, ctx, nil, "admin_setLatestProvenCertificate", certificateID)
+}
+
+// RemovePendingCertificate removes the pending certificate of the network at
+// the given height. When removeSoftly is set the certificate is only removed
+// from the pending queue and kept in the certificate store.
+func (c *AdminClient) RemovePendingCertificate(t *testing.T, ctx context.Context, networkID NetworkID, height Height, removeSoftly bool) error {
+	return c.rpc.CallResult(t, ctx, nil, "admin_removePendingCertificate", networkID, height, removeSoftly)
+}
+
+// RemovePendingProof removes the proof generated for the certificate
+func (c *AdminClient) RemovePendingProof(t *testing.T, ctx context.Context, certificateID CertificateID) error {
+	return c.rpc.CallResult(t, ctx, nil, "admin_removePendingProof", certificateID)
+}
+
+// ForcePushPendingCertificate stores the certificate as pending with the
+// given status, bypassing the usual checks
+func (c *AdminClient) ForcePushPendingCertificate(t *testing.T, ctx context.Context, certificate *Certificate, status CertificateStatus) error {
+	return c.rpc.CallResult(t, ctx, nil, "admin_forcePushPendingCertificate", certificate, status)
+}
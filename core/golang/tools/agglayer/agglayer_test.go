@@ -0,0 +1,161 @@
+package agglayer
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	settledHeader = `{"network_id":1,"height":3,"epoch_number":7,"certificate_index":0,` +
+		`"certificate_id":"0x0000000000000000000000000000000000000000000000000000000000000001",` +
+		`"prev_local_exit_root":"0x0000000000000000000000000000000000000000000000000000000000000002",` +
+		`"new_local_exit_root":"0x0000000000000000000000000000000000000000000000000000000000000003",` +
+		`"metadata":"0x0000000000000000000000000000000000000000000000000000000000000000",` +
+		`"status":"Settled",` +
+		`"settlement_tx_hash":"0x0000000000000000000000000000000000000000000000000000000000000004"}`
+	inErrorHeader = `{"network_id":1,"height":4,"epoch_number":null,"certificate_index":null,` +
+		`"certificate_id":"0x0000000000000000000000000000000000000000000000000000000000000005",` +
+		`"prev_local_exit_root":"0x0000000000000000000000000000000000000000000000000000000000000003",` +
+		`"new_local_exit_root":"0x0000000000000000000000000000000000000000000000000000000000000006",` +
+		`"metadata":"0x0000000000000000000000000000000000000000000000000000000000000000",` +
+		`"status":{"InError":{"error":{"ProofVerificationFailed":"invalid signature"}}},` +
+		`"settlement_tx_hash":null}`
+)
+
+// newTestServer answers each method with its JSON result, recording the
+// params of the last call of each method when calls is set
+func newTestServer(t *testing.T, results map[string]string, calls map[string][]json.RawMessage) *httptest.Server {
+	var mu sync.Mutex
+	handlers := map[string]engine.TestHandler{}
+	for method, result := range results {
+		handlers[method] = func(params json.RawMessage) (any, error) {
+			if calls != nil {
+				var list []json.RawMessage
+				if err := json.Unmarshal(params, &list); err != nil {
+					return nil, err
+				}
+				mu.Lock()
+				calls[method] = list
+				mu.Unlock()
+			}
+			return json.RawMessage(result), nil
+		}
+	}
+	return engine.NewTestServer(t, handlers)
+}
+
+func TestClient(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"interop_getCertificateHeader":              inErrorHeader,
+		"interop_getLatestSettledCertificateHeader": settledHeader,
+		"interop_getLatestPendingCertificateHeader": `null`,
+		"interop_getEpochConfiguration":             `{"genesis_block":10,"epoch_duration":15}`,
+		"interop_getTxStatus":                       `"done"`,
+		"interop_sendCertificate":                   `"0x0000000000000000000000000000000000000000000000000000000000000005"`,
+	}, nil)
+
+	ctx := context.Background()
+	client := NewClient(server.URL)
+
+	header, err := client.CertificateHeader(t, ctx, common.HexToHash("0x5"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusInError, header.Status)
+	assert.True(t, header.Status.IsFinal())
+	assert.JSONEq(t, `{"ProofVerificationFailed":"invalid signature"}`, string(header.Error))
+	assert.Nil(t, header.EpochNumber)
+	assert.Nil(t, header.SettlementTxHash)
+
+	settled, err := client.LatestSettledCertificateHeader(t, ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSettled, settled.Status)
+	assert.Equal(t, NetworkID(1), settled.NetworkID)
+	assert.Equal(t, Height(3), settled.Height)
+	assert.Equal(t, EpochNumber(7), *settled.EpochNumber)
+	assert.Equal(t, common.HexToHash("0x4"), *settled.SettlementTxHash)
+
+	pending, err := client.LatestPendingCertificateHeader(t, ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, pending)
+
+	epochs, err := client.EpochConfiguration(t, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, EpochConfiguration{GenesisBlock: 10, EpochDuration: 15}, *epochs)
+
+	txStatus, err := client.TxStatus(t, ctx, common.HexToHash("0x4"))
+	require.NoError(t, err)
+	assert.Equal(t, TxStatusDone, txStatus)
+
+	certificateID, err := client.SendCertificate(t, ctx, &Certificate{NetworkID: 1, Height: 4})
+	require.NoError(t, err)
+	assert.Equal(t, common.HexToHash("0x5"), certificateID)
+}
+
+func TestAdminClient(t *testing.T) {
+	calls := map[string][]json.RawMessage{}
+	server := newTestServer(t, map[string]string{
+		"admin_getCertificate": `[{"network_id":1,"height":3,` +
+			`"prev_local_exit_root":"0x0000000000000000000000000000000000000000000000000000000000000002",` +
+			`"new_local_exit_root":"0x0000000000000000000000000000000000000000000000000000000000000003",` +
+			`"bridge_exits":[{"leaf_type":"Transfer","token_info":{"origin_network":0,"origin_token_address":"0x0000000000000000000000000000000000000000"},` +
+			`"dest_network":0,"dest_address":"0x0000000000000000000000000000000000000001","amount":"0x64","metadata":null}],` +
+			`"imported_bridge_exits":[],` +
+			`"metadata":"0x0000000000000000000000000000000000000000000000000000000000000000"},` + settledHeader + `]`,
+		"admin_removePendingCertificate": `null`,
+		"admin_removePendingProof":       `null`,
+	}, calls)
+
+	ctx := context.Background()
+	client := NewAdminClientFrom(engine.NewClient(server.URL))
+
+	certificate, header, err := client.GetCertificate(t, ctx, common.HexToHash("0x1"))
+	require.NoError(t, err)
+	assert.Equal(t, Height(3), certificate.Height)
+	require.Len(t, certificate.BridgeExits, 1)
+	assert.Equal(t, LeafTypeTransfer, certificate.BridgeExits[0].LeafType)
+	assert.Equal(t, big.NewInt(100), certificate.BridgeExits[0].Amount.Int)
+	assert.Equal(t, StatusSettled, header.Status)
+
+	require.NoError(t, client.RemovePendingCertificate(t, ctx, 1, 4, true))
+	require.Len(t, calls["admin_removePendingCertificate"], 3)
+	assert.JSONEq(t, `true`, string(calls["admin_removePendingCertificate"][2]))
+
+	require.NoError(t, client.RemovePendingProof(t, ctx, common.HexToHash("0x1")))
+}
+
+func TestCertificateHeaderJSON(t *testing.T) {
+	for _, input := range []string{settledHeader, inErrorHeader} {
+		var header CertificateHeader
+		require.NoError(t, json.Unmarshal([]byte(input), &header))
+		encoded, err := json.Marshal(header)
+		require.NoError(t, err)
+		assert.JSONEq(t, input, string(encoded))
+	}
+
+	var header CertificateHeader
+	require.Error(t, json.Unmarshal([]byte(`{"status":{"Unknown":{}}}`), &header))
+}
+
+func TestU256(t *testing.T) {
+	for input, expected := range map[string]int64{`"0x64"`: 100, `"100"`: 100, `100`: 100, `"0x0"`: 0} {
+		var u U256
+		require.NoError(t, json.Unmarshal([]byte(input), &u), input)
+		assert.Equal(t, expected, u.Int64(), input)
+	}
+
+	var u U256
+	require.Error(t, json.Unmarshal([]byte(`"-1"`), &u))
+	require.Error(t, json.Unmarshal([]byte(`"0xzz"`), &u))
+
+	encoded, err := json.Marshal(NewU256(big.NewInt(255)))
+	require.NoError(t, err)
+	assert.Equal(t, `"0xff"`, string(encoded))
+}
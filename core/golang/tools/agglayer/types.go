@@ -0,0 +1,247 @@
+package agglayer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/agglayer/e2e/core/golang/tools/hex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NetworkID identifies a chain attached to the agglayer
+type NetworkID uint32
+
+// Height is the position of a certificate in the chain of certificates of a network
+type Height uint64
+
+// EpochNumber identifies an agglayer epoch
+type EpochNumber uint64
+
+// CertificateIndex is the position of a certificate inside an epoch
+type CertificateIndex uint64
+
+// CertificateID is the hash identifying a certificate
+type CertificateID = common.Hash
+
+// CertificateStatus is the status of a certificate in the agglayer
+type CertificateStatus string
+
+const (
+	// StatusPending means the certificate was received and awaits a proof
+	StatusPending CertificateStatus = "Pending"
+	// StatusProven means the pessimistic proof of the certificate was generated
+	StatusProven CertificateStatus = "Proven"
+	// StatusCandidate means the certificate is ready to be settled on L1
+	StatusCandidate CertificateStatus = "Candidate"
+	// StatusInError means the certificate was rejected, see CertificateHeader.Error
+	StatusInError CertificateStatus = "InError"
+	// StatusSettled means the certificate was settled on L1
+	StatusSettled CertificateStatus = "Settled"
+)
+
+// IsFinal reports whether the certificate can't change its status anymore
+func (s CertificateStatus) IsFinal() bool {
+	return s == StatusSettled || s == StatusInError
+}
+
+// TxStatus is the status of a settlement tx as returned by interop_getTxStatus
+type TxStatus string
+
+const (
+	TxStatusPending  TxStatus = "pending"
+	TxStatusDone     TxStatus = "done"
+	TxStatusNotFound TxStatus = "not found"
+)
+
+// CertificateHeader is the summary of a certificate returned by the interop
+// and admin certificate header methods
+type CertificateHeader struct {
+	NetworkID         NetworkID         `json:"network_id"`
+	Height            Height            `json:"height"`
+	EpochNumber       *EpochNumber      `json:"epoch_number"`
+	CertificateIndex  *CertificateIndex `json:"certificate_index"`
+	CertificateID     CertificateID     `json:"certificate_id"`
+	PrevLocalExitRoot common.Hash       `json:"prev_local_exit_root"`
+	NewLocalExitRoot  common.Hash       `json:"new_local_exit_root"`
+	Metadata          common.Hash       `json:"metadata"`
+	Status            CertificateStatus `json:"-"`
+	// Error holds the details of the failure when the status is InError
+	Error            json.RawMessage `json:"-"`
+	SettlementTxHash *common.Hash    `json:"settlement_tx_hash"`
+}
+
+// certificateHeaderJSON is the wire format of CertificateHeader, whose
+// status is either a string or an object like {"InError": {"error": ...}}
+type certificateHeaderJSON struct {
+	certificateHeaderFields
+	Status json.RawMessage `json:"status"`
+}
+
+type certificateHeaderFields CertificateHeader
+
+// MarshalJSON encodes the header with the status in the agglayer format
+func (h CertificateHeader) MarshalJSON() ([]byte, error) {
+	var status any = h.Status
+	if h.Status == StatusInError {
+		errorDetails := h.Error
+		if len(errorDetails) == 0 {
+			errorDetails = json.RawMessage("null")
+		}
+		status = map[CertificateStatus]any{StatusInError: map[string]json.RawMessage{"error": errorDetails}}
+	}
+
+	rawStatus, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(certificateHeaderJSON{
+		certificateHeaderFields: certificateHeaderFields(h),
+		Status:                  rawStatus,
+	})
+}
+
+// UnmarshalJSON decodes the header, including InError statuses
+func (h *CertificateHeader) UnmarshalJSON(input []byte) error {
+	var dec certificateHeaderJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*h = CertificateHeader(dec.certificateHeaderFields)
+
+	var status string
+	if err := json.Unmarshal(dec.Status, &status); err == nil {
+		h.Status = CertificateStatus(status)
+		return nil
+	}
+
+	var inError map[string]struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(dec.Status, &inError); err != nil {
+		return fmt.Errorf("invalid certificate status %v: %w", string(dec.Status), err)
+	}
+	details, found := inError[string(StatusInError)]
+	if !found {
+		return fmt.Errorf("invalid certificate status %v", string(dec.Status))
+	}
+	h.Status = StatusInError
+	h.Error = details.Error
+	return nil
+}
+
+// String returns a one line description of the header
+func (h *CertificateHeader) String() string {
+	return fmt.Sprintf("certificate %v of network %v at height %v with status %v",
+		h.CertificateID.String(), h.NetworkID, h.Height, h.Status)
+}
+
+// EpochConfiguration is the result of interop_getEpochConfiguration
+type EpochConfiguration struct {
+	GenesisBlock  uint64 `json:"genesis_block"`
+	EpochDuration uint64 `json:"epoch_duration"`
+}
+
+// U256 is an unsigned 256 bits integer encoded as a hex string, decimal
+// strings and JSON numbers are accepted when decoding
+type U256 struct {
+	*big.Int
+}
+
+// NewU256 wraps the value, nil is handled as zero
+func NewU256(v *big.Int) U256 {
+	if v == nil {
+		v = new(big.Int)
+	}
+	return U256{Int: new(big.Int).Set(v)}
+}
+
+// MarshalJSON encodes the value as a hex string
+func (u U256) MarshalJSON() ([]byte, error) {
+	v := u.Int
+	if v == nil {
+		v = new(big.Int)
+	}
+	return json.Marshal(hex.EncodeBig(v))
+}
+
+// UnmarshalJSON decodes hex strings, decimal strings and numbers
+func (u *U256) UnmarshalJSON(input []byte) error {
+	str := strings.Trim(string(input), `"`)
+	base := 10 //nolint:mnd
+	if strings.HasPrefix(str, "0x") {
+		str, base = strings.TrimPrefix(str, "0x"), hex.Base
+	}
+	v, ok := new(big.Int).SetString(str, base)
+	if !ok || v.Sign() < 0 {
+		return fmt.Errorf("invalid U256 %v", string(input))
+	}
+	u.Int = v
+	return nil
+}
+
+// LeafType is the type of a bridge exit
+type LeafType string
+
+const (
+	LeafTypeTransfer LeafType = "Transfer"
+	LeafTypeMessage  LeafType = "Message"
+)
+
+// TokenInfo identifies a token by its origin network and address
+type TokenInfo struct {
+	OriginNetwork      NetworkID      `json:"origin_network"`
+	OriginTokenAddress common.Address `json:"origin_token_address"`
+}
+
+// BridgeExit is a leaf of a local exit tree
+type BridgeExit struct {
+	LeafType    LeafType       `json:"leaf_type"`
+	TokenInfo   TokenInfo      `json:"token_info"`
+	DestNetwork NetworkID      `json:"dest_network"`
+	DestAddress common.Address `json:"dest_address"`
+	Amount      U256           `json:"amount"`
+	Metadata    *common.Hash   `json:"metadata"`
+}
+
+// GlobalIndex locates a claimed bridge exit in the exit trees
+type GlobalIndex struct {
+	MainnetFlag bool   `json:"mainnet_flag"`
+	RollupIndex uint32 `json:"rollup_index"`
+	LeafIndex   uint32 `json:"leaf_index"`
+}
+
+// ImportedBridgeExit is a bridge exit claimed on the network emitting the certificate
+type ImportedBridgeExit struct {
+	BridgeExit  BridgeExit      `json:"bridge_exit"`
+	ClaimData   json.RawMessage `json:"claim_data"`
+	GlobalIndex GlobalIndex     `json:"global_index"`
+}
+
+// Certificate is the state transition of a network submitted to the agglayer
+type Certificate struct {
+	NetworkID           NetworkID            `json:"network_id"`
+	Height              Height               `json:"height"`
+	PrevLocalExitRoot   common.Hash          `json:"prev_local_exit_root"`
+	NewLocalExitRoot    common.Hash          `json:"new_local_exit_root"`
+	BridgeExits         []BridgeExit         `json:"bridge_exits"`
+	ImportedBridgeExits []ImportedBridgeExit `json:"imported_bridge_exits"`
+	Metadata            common.Hash          `json:"metadata"`
+	L1InfoTreeLeafCount *uint32              `json:"l1_info_tree_leaf_count,omitempty"`
+	Signature           json.RawMessage      `json:"signature,omitempty"`
+	AggchainData        json.RawMessage      `json:"aggchain_data,omitempty"`
+	CustomChainData     json.RawMessage      `json:"custom_chain_data,omitempty"`
+}
+
+// ImportsGlobalIndex reports whether the certificate claims the bridge exit
+// with the given global index
+func (c *Certificate) ImportsGlobalIndex(globalIndex GlobalIndex) bool {
+	for _, imported := range c.ImportedBridgeExits {
+		if imported.GlobalIndex == globalIndex {
+			return true
+		}
+	}
+	return false
+}
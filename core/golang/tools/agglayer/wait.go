@@ -0,0 +1,256 @@
+package agglayer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/agglayer/e2e/core/golang/tools/log"
+)
+
+// TimeoutCertificateToSettle is the usual time for a certificate to be proven
+// and settled on L1
+const TimeoutCertificateToSettle = 20 * time.Minute
+
+// CertificatePollInterval is the time between two checks of the certificate waits
+var CertificatePollInterval = 5 * time.Second
+
+// StatusChange is a status observed for a certificate
+type StatusChange struct {
+	CertificateID CertificateID
+	NetworkID     NetworkID
+	Height        Height
+	Status        CertificateStatus
+	ObservedAt    time.Time
+}
+
+// Timeline is the list of status changes observed while waiting, in order
+type Timeline []StatusChange
+
+// observe records the header status if it differs from the last status
+// observed for the same certificate, and reports whether it was recorded
+func (tl *Timeline) observe(header *CertificateHeader) bool {
+	if header == nil {
+		return false
+	}
+	for i := len(*tl) - 1; i >= 0; i-- {
+		change := (*tl)[i]
+		if change.CertificateID == header.CertificateID {
+			if change.Status == header.Status {
+				return false
+			}
+			break
+		}
+	}
+	*tl = append(*tl, StatusChange{
+		CertificateID: header.CertificateID,
+		NetworkID:     header.NetworkID,
+		Height:        header.Height,
+		Status:        header.Status,
+		ObservedAt:    time.Now(),
+	})
+	return true
+}
+
+// Certificate returns the changes observed for the given certificate
+func (tl Timeline) Certificate(certificateID CertificateID) Timeline {
+	var changes Timeline
+	for _, change := range tl {
+		if change.CertificateID == certificateID {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// String returns one line per change
+func (tl Timeline) String() string {
+	lines := make([]string, len(tl))
+	for i, change := range tl {
+		lines[i] = fmt.Sprintf("%v certificate %v of network %v at height %v: %v",
+			change.ObservedAt.Format(time.RFC3339), change.CertificateID.String(), change.NetworkID, change.Height, change.Status)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// certificateWatcher follows the latest certificates of a network
+type certificateWatcher struct {
+	client    *Client
+	networkID NetworkID
+	timeline  Timeline
+}
+
+// latestKnown returns the latest known header, recording its status
+func (w *certificateWatcher) latestKnown(t *testing.T, ctx context.Context) (*CertificateHeader, error) {
+	header, err := w.client.LatestKnownCertificateHeader(t, ctx, w.networkID)
+	return w.record(t, header, err)
+}
+
+// latestSettled returns the latest settled header, recording its status
+func (w *certificateWatcher) latestSettled(t *testing.T, ctx context.Context) (*CertificateHeader, error) {
+	header, err := w.client.LatestSettledCertificateHeader(t, ctx, w.networkID)
+	return w.record(t, header, err)
+}
+
+// latestPending returns the latest pending header, recording its status
+func (w *certificateWatcher) latestPending(t *testing.T, ctx context.Context) (*CertificateHeader, error) {
+	header, err := w.client.LatestPendingCertificateHeader(t, ctx, w.networkID)
+	return w.record(t, header, err)
+}
+
+// certificate returns the header of the certificate, recording its status
+func (w *certificateWatcher) certificate(t *testing.T, ctx context.Context, certificateID CertificateID) (*CertificateHeader, error) {
+	header, err := w.client.CertificateHeader(t, ctx, certificateID)
+	return w.record(t, header, err)
+}
+
+func (w *certificateWatcher) record(t *testing.T, header *CertificateHeader, err error) (*CertificateHeader, error) {
+	if err != nil {
+		return nil, err
+	}
+	if w.timeline.observe(header) {
+		log.Msgf(t, "%v", header.String())
+	}
+	return header, nil
+}
+
+// WaitCertificateSettledAtHeight waits until a certificate of the network
+// with a height greater or equal to the given one is settled. It returns the
+// latest settled header and the status changes observed for the network.
+func WaitCertificateSettledAtHeight(t *testing.T, ctx context.Context, client *Client, networkID NetworkID, height Height, timeout time.Duration) (*CertificateHeader, Timeline, error) {
+	watcher := &certificateWatcher{client: client, networkID: networkID}
+	description := fmt.Sprintf("certificate of network %v with height >= %v to be settled", networkID, height)
+
+	var settled *CertificateHeader
+	err := engine.WaitFor(t, ctx, description, engine.Every(CertificatePollInterval), timeout, func(ctx context.Context) (bool, error) {
+		if _, err := watcher.latestKnown(t, ctx); err != nil {
+			return false, err
+		}
+
+		var err error
+		settled, err = watcher.latestSettled(t, ctx)
+		if err != nil {
+			return false, err
+		}
+		if settled == nil {
+			return false, engine.NotReady("no certificate settled yet")
+		}
+		if settled.Height < height {
+			return false, engine.NotReady("latest settled certificate at height %v", settled.Height)
+		}
+		return true, nil
+	})
+
+	return settled, watcher.timeline, err
+}
+
+// WaitCertificateWithGlobalIndexSettled waits until the certificate of the
+// network importing the bridge exit with the given global index is settled.
+// Every certificate seen as the latest known, pending or settled one of the
+// network is fetched with the admin client to check its imported bridge
+// exits, so the certificate is found even if it was settled before the wait
+// or a newer one was sent after it. A certificate in error keeps the wait
+// going since the network is expected to send it again. It returns the
+// header of the settled certificate and the status changes observed for the
+// network.
+func WaitCertificateWithGlobalIndexSettled(t *testing.T, ctx context.Context, client *Client, admin *AdminClient, networkID NetworkID, globalIndex GlobalIndex, timeout time.Duration) (*CertificateHeader, Timeline, error) {
+	watcher := &certificateWatcher{client: client, networkID: networkID}
+	description := fmt.Sprintf("certificate of network %v with global index %+v to be settled", networkID, globalIndex)
+
+	// checked caches whether each certificate seen imports the global index
+	checked := map[CertificateID]bool{}
+	var candidate *CertificateHeader
+	err := engine.WaitFor(t, ctx, description, engine.Every(CertificatePollInterval), timeout, func(ctx context.Context) (bool, error) {
+		if candidate != nil && !candidate.Status.IsFinal() {
+			header, err := watcher.certificate(t, ctx, candidate.CertificateID)
+			if err != nil {
+				return false, err
+			}
+			if header != nil {
+				candidate = header
+			}
+			if candidate.Status == StatusSettled {
+				return true, nil
+			}
+		}
+
+		latest, err := watcher.latestKnown(t, ctx)
+		if err != nil {
+			return false, err
+		}
+		pending, err := watcher.latestPending(t, ctx)
+		if err != nil {
+			return false, err
+		}
+		settled, err := watcher.latestSettled(t, ctx)
+		if err != nil {
+			return false, err
+		}
+		if latest == nil {
+			return false, engine.NotReady("no certificate sent yet")
+		}
+
+		// the newest certificate importing the global index wins, e.g. the
+		// one sent again after an error
+		for _, header := range []*CertificateHeader{settled, pending, latest} {
+			if header == nil {
+				continue
+			}
+			contains, found := checked[header.CertificateID]
+			if !found {
+				certificate, _, err := admin.GetCertificate(t, ctx, header.CertificateID)
+				if err != nil {
+					return false, err
+				}
+				contains = certificate != nil && certificate.ImportsGlobalIndex(globalIndex)
+				checked[header.CertificateID] = contains
+				if contains {
+					log.Msgf(t, "global index %+v found in certificate %v at height %v", globalIndex, header.CertificateID.String(), header.Height)
+				}
+			}
+			if contains {
+				candidate = header
+			}
+		}
+
+		switch {
+		case candidate == nil:
+			return false, engine.NotReady("global index not found, latest certificate at height %v with status %v", latest.Height, latest.Status)
+		case candidate.Status == StatusSettled:
+			return true, nil
+		default:
+			return false, engine.NotReady("global index in certificate %v at height %v with status %v", candidate.CertificateID.String(), candidate.Height, candidate.Status)
+		}
+	})
+
+	return candidate, watcher.timeline, err
+}
+
+// WaitPendingCertificateInError waits until the latest pending certificate
+// of the network is in error. It returns its header, whose Error field
+// describes the failure, and the status changes observed for the network.
+func WaitPendingCertificateInError(t *testing.T, ctx context.Context, client *Client, networkID NetworkID, timeout time.Duration) (*CertificateHeader, Timeline, error) {
+	watcher := &certificateWatcher{client: client, networkID: networkID}
+	description := fmt.Sprintf("pending certificate of network %v to be in error", networkID)
+
+	var pending *CertificateHeader
+	err := engine.WaitFor(t, ctx, description, engine.Every(CertificatePollInterval), timeout, func(ctx context.Context) (bool, error) {
+		var err error
+		pending, err = watcher.latestPending(t, ctx)
+		if err != nil {
+			return false, err
+		}
+		if pending == nil {
+			return false, engine.NotReady("no pending certificate")
+		}
+		if pending.Status != StatusInError {
+			return false, engine.NotReady("pending certificate at height %v with status %v", pending.Height, pending.Status)
+		}
+		return true, nil
+	})
+
+	return pending, watcher.timeline, err
+}
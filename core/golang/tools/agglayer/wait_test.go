@@ -0,0 +1,135 @@
+package agglayer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newScriptedServer returns the results of each method in order, repeating
+// the last one once all of them were returned
+func newScriptedServer(t *testing.T, script map[string][]any) *httptest.Server {
+	var mu sync.Mutex
+	handlers := map[string]engine.TestHandler{}
+	for method := range script {
+		handlers[method] = func(json.RawMessage) (any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			results := script[method]
+			if len(results) > 1 {
+				script[method] = results[1:]
+			}
+			return results[0], nil
+		}
+	}
+	return engine.NewTestServer(t, handlers)
+}
+
+func testHeader(id int64, height Height, status CertificateStatus) *CertificateHeader {
+	return &CertificateHeader{
+		NetworkID:     1,
+		Height:        height,
+		CertificateID: common.BigToHash(big.NewInt(id)),
+		Status:        status,
+	}
+}
+
+func setPollInterval(t *testing.T) {
+	previous := CertificatePollInterval
+	CertificatePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { CertificatePollInterval = previous })
+}
+
+func TestWaitCertificateSettledAtHeight(t *testing.T) {
+	setPollInterval(t)
+	server := newScriptedServer(t, map[string][]any{
+		"interop_getLatestKnownCertificateHeader": {
+			testHeader(1, 2, StatusPending), testHeader(1, 2, StatusProven), testHeader(1, 2, StatusSettled),
+		},
+		"interop_getLatestSettledCertificateHeader": {
+			nil, testHeader(0, 1, StatusSettled), testHeader(1, 2, StatusSettled),
+		},
+	})
+
+	header, timeline, err := WaitCertificateSettledAtHeight(t, context.Background(), NewClient(server.URL), 1, 2, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, Height(2), header.Height)
+
+	statuses := []CertificateStatus{}
+	for _, change := range timeline.Certificate(header.CertificateID) {
+		statuses = append(statuses, change.Status)
+	}
+	assert.Equal(t, []CertificateStatus{StatusPending, StatusProven, StatusSettled}, statuses)
+	assert.Len(t, timeline, 4)
+}
+
+func TestWaitCertificateWithGlobalIndexSettled(t *testing.T) {
+	setPollInterval(t)
+	globalIndex := GlobalIndex{MainnetFlag: true, LeafIndex: 7}
+	withoutIndex := &Certificate{NetworkID: 1, Height: 2}
+	withIndex := &Certificate{NetworkID: 1, Height: 3, ImportedBridgeExits: []ImportedBridgeExit{{GlobalIndex: globalIndex}}}
+
+	server := newScriptedServer(t, map[string][]any{
+		"interop_getLatestKnownCertificateHeader": {
+			testHeader(2, 2, StatusSettled), testHeader(3, 3, StatusPending), testHeader(3, 3, StatusCandidate),
+		},
+		"interop_getLatestPendingCertificateHeader": {
+			nil, testHeader(3, 3, StatusPending), testHeader(3, 3, StatusCandidate),
+		},
+		"interop_getLatestSettledCertificateHeader": {
+			testHeader(2, 2, StatusSettled),
+		},
+		"admin_getCertificate": {
+			[]any{withoutIndex, nil}, []any{withIndex, nil},
+		},
+		"interop_getCertificateHeader": {
+			testHeader(3, 3, StatusCandidate), testHeader(3, 3, StatusSettled),
+		},
+	})
+
+	header, timeline, err := WaitCertificateWithGlobalIndexSettled(t, context.Background(), NewClient(server.URL), NewAdminClient(server.URL), 1, globalIndex, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, Height(3), header.Height)
+	assert.Equal(t, StatusSettled, header.Status)
+	assert.Len(t, timeline.Certificate(header.CertificateID), 3)
+}
+
+func TestWaitPendingCertificateInError(t *testing.T) {
+	setPollInterval(t)
+	inError := testHeader(4, 4, StatusInError)
+	inError.Error = json.RawMessage(`{"TypeConversionError":"overflow"}`)
+	server := newScriptedServer(t, map[string][]any{
+		"interop_getLatestPendingCertificateHeader": {nil, testHeader(4, 4, StatusPending), inError},
+	})
+
+	header, timeline, err := WaitPendingCertificateInError(t, context.Background(), NewClient(server.URL), 1, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, StatusInError, header.Status)
+	assert.JSONEq(t, `{"TypeConversionError":"overflow"}`, string(header.Error))
+	assert.Len(t, timeline, 2)
+	assert.Contains(t, timeline.String(), "at height 4: InError")
+}
+
+func TestWaitCertificateTimeout(t *testing.T) {
+	setPollInterval(t)
+	server := newScriptedServer(t, map[string][]any{
+		"interop_getLatestKnownCertificateHeader":   {testHeader(1, 1, StatusPending)},
+		"interop_getLatestSettledCertificateHeader": {testHeader(0, 0, StatusSettled)},
+	})
+
+	_, timeline, err := WaitCertificateSettledAtHeight(t, context.Background(), NewClient(server.URL), 1, 1, 100*time.Millisecond)
+	var timeoutErr *engine.WaitTimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, "latest settled certificate at height 0", timeoutErr.LastState)
+	assert.Len(t, timeline, 2)
+}
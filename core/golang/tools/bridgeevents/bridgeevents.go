@@ -0,0 +1,333 @@
+package bridgeevents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// bridgeABI holds the events of the PolygonZkEVMBridgeV2 contract, the
+// legacy ClaimEvent is the one emitted before etrog, with a deposit index
+// instead of a global index
+const bridgeABI = `[
+	{"type":"event","name":"BridgeEvent","anonymous":false,"inputs":[
+		{"name":"leafType","type":"uint8","indexed":false},
+		{"name":"originNetwork","type":"uint32","indexed":false},
+		{"name":"originAddress","type":"address","indexed":false},
+		{"name":"destinationNetwork","type":"uint32","indexed":false},
+		{"name":"destinationAddress","type":"address","indexed":false},
+		{"name":"amount","type":"uint256","indexed":false},
+		{"name":"metadata","type":"bytes","indexed":false},
+		{"name":"depositCount","type":"uint32","indexed":false}]},
+	{"type":"event","name":"ClaimEvent","anonymous":false,"inputs":[
+		{"name":"globalIndex","type":"uint256","indexed":false},
+		{"name":"originNetwork","type":"uint32","indexed":false},
+		{"name":"originAddress","type":"address","indexed":false},
+		{"name":"destinationAddress","type":"address","indexed":false},
+		{"name":"amount","type":"uint256","indexed":false}]}
+]`
+
+const legacyClaimEventABI = `[
+	{"type":"event","name":"ClaimEvent","anonymous":false,"inputs":[
+		{"name":"index","type":"uint32","indexed":false},
+		{"name":"originNetwork","type":"uint32","indexed":false},
+		{"name":"originAddress","type":"address","indexed":false},
+		{"name":"destinationAddress","type":"address","indexed":false},
+		{"name":"amount","type":"uint256","indexed":false}]}
+]`
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+var (
+	// BridgeABI is the parsed ABI of the bridge events
+	BridgeABI         = mustParseABI(bridgeABI)
+	legacyClaimEvents = mustParseABI(legacyClaimEventABI)
+
+	// BridgeEventTopic is the topic of the BridgeEvent log
+	BridgeEventTopic = BridgeABI.Events["BridgeEvent"].ID
+	// ClaimEventTopic is the topic of the ClaimEvent log
+	ClaimEventTopic = BridgeABI.Events["ClaimEvent"].ID
+	// LegacyClaimEventTopic is the topic of the ClaimEvent log emitted by
+	// the bridge before etrog
+	LegacyClaimEventTopic = legacyClaimEvents.Events["ClaimEvent"].ID
+
+	// ErrUnexpectedEvent is returned when decoding a log of another event
+	ErrUnexpectedEvent = errors.New("unexpected event")
+	// ErrReceiptNotFound is returned when the tx is not mined
+	ErrReceiptNotFound = errors.New("receipt not found")
+)
+
+// LogRef locates the log an event was decoded from
+type LogRef struct {
+	Address     common.Address `json:"address"`
+	TxHash      common.Hash    `json:"txHash"`
+	BlockNumber uint64         `json:"blockNumber"`
+	LogIndex    uint           `json:"logIndex"`
+}
+
+func newLogRef(log *types.Log) LogRef {
+	return LogRef{Address: log.Address, TxHash: log.TxHash, BlockNumber: log.BlockNumber, LogIndex: log.Index}
+}
+
+// BridgeEvent is a decoded BridgeEvent log, emitted for each deposit. The
+// amount is encoded in JSON as a decimal string, as jq loses the precision
+// of big numbers.
+type BridgeEvent struct {
+	LogRef
+	LeafType           bridgeservice.LeafType `json:"leafType"`
+	OriginNetwork      uint32                 `json:"originNetwork"`
+	OriginAddress      common.Address         `json:"originAddress"`
+	DestinationNetwork uint32                 `json:"destinationNetwork"`
+	DestinationAddress common.Address         `json:"destinationAddress"`
+	Amount             *big.Int               `json:"amount"`
+	Metadata           hexutil.Bytes          `json:"metadata"`
+	DepositCount       uint32                 `json:"depositCount"`
+}
+
+// ClaimEvent is a decoded ClaimEvent log, emitted for each claim. The global
+// index and the amount are encoded in JSON as decimal strings.
+type ClaimEvent struct {
+	LogRef
+	// GlobalIndex is the deposit index for the events emitted before etrog
+	GlobalIndex        *big.Int       `json:"globalIndex"`
+	OriginNetwork      uint32         `json:"originNetwork"`
+	OriginAddress      common.Address `json:"originAddress"`
+	DestinationAddress common.Address `json:"destinationAddress"`
+	Amount             *big.Int       `json:"amount"`
+	// Legacy reports whether the event was emitted before etrog
+	Legacy bool `json:"legacy"`
+}
+
+// MarshalJSON encodes the amount as a decimal string
+func (e BridgeEvent) MarshalJSON() ([]byte, error) {
+	type alias BridgeEvent
+	return json.Marshal(struct {
+		alias
+		Amount *bridgeservice.BigIntString `json:"amount"`
+	}{alias: alias(e), Amount: bigIntString(e.Amount)})
+}
+
+// UnmarshalJSON decodes the amount from a decimal string or a number
+func (e *BridgeEvent) UnmarshalJSON(input []byte) error {
+	type alias BridgeEvent
+	decoded := struct {
+		*alias
+		Amount *bridgeservice.BigIntString `json:"amount"`
+	}{alias: (*alias)(e)}
+	if err := json.Unmarshal(input, &decoded); err != nil {
+		return err
+	}
+	e.Amount = bigInt(decoded.Amount)
+	return nil
+}
+
+// MarshalJSON encodes the global index and the amount as decimal strings
+func (e ClaimEvent) MarshalJSON() ([]byte, error) {
+	type alias ClaimEvent
+	return json.Marshal(struct {
+		alias
+		GlobalIndex *bridgeservice.BigIntString `json:"globalIndex"`
+		Amount      *bridgeservice.BigIntString `json:"amount"`
+	}{alias: alias(e), GlobalIndex: bigIntString(e.GlobalIndex), Amount: bigIntString(e.Amount)})
+}
+
+// UnmarshalJSON decodes the global index and the amount from decimal strings
+// or numbers
+func (e *ClaimEvent) UnmarshalJSON(input []byte) error {
+	type alias ClaimEvent
+	decoded := struct {
+		*alias
+		GlobalIndex *bridgeservice.BigIntString `json:"globalIndex"`
+		Amount      *bridgeservice.BigIntString `json:"amount"`
+	}{alias: (*alias)(e)}
+	if err := json.Unmarshal(input, &decoded); err != nil {
+		return err
+	}
+	e.GlobalIndex = bigInt(decoded.GlobalIndex)
+	e.Amount = bigInt(decoded.Amount)
+	return nil
+}
+
+// bigIntString wraps the value, keeping nil as null
+func bigIntString(v *big.Int) *bridgeservice.BigIntString {
+	if v == nil {
+		return nil
+	}
+	return bridgeservice.NewBigIntString(v)
+}
+
+func bigInt(v *bridgeservice.BigIntString) *big.Int {
+	if v == nil {
+		return nil
+	}
+	return v.Int
+}
+
+// Events holds the bridge events of a receipt, in log order
+type Events struct {
+	BridgeEvents []*BridgeEvent `json:"bridgeEvents"`
+	ClaimEvents  []*ClaimEvent  `json:"claimEvents"`
+}
+
+// DecodeBridgeEvent decodes a BridgeEvent log
+func DecodeBridgeEvent(log *types.Log) (*BridgeEvent, error) {
+	if len(log.Topics) == 0 || log.Topics[0] != BridgeEventTopic {
+		return nil, ErrUnexpectedEvent
+	}
+	var event struct {
+		LeafType           uint8
+		OriginNetwork      uint32
+		OriginAddress      common.Address
+		DestinationNetwork uint32
+		DestinationAddress common.Address
+		Amount             *big.Int
+		Metadata           []byte
+		DepositCount       uint32
+	}
+	if err := BridgeABI.UnpackIntoInterface(&event, "BridgeEvent", log.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode BridgeEvent: %w", err)
+	}
+	return &BridgeEvent{
+		LogRef:             newLogRef(log),
+		LeafType:           bridgeservice.LeafType(event.LeafType),
+		OriginNetwork:      event.OriginNetwork,
+		OriginAddress:      event.OriginAddress,
+		DestinationNetwork: event.DestinationNetwork,
+		DestinationAddress: event.DestinationAddress,
+		Amount:             event.Amount,
+		Metadata:           event.Metadata,
+		DepositCount:       event.DepositCount,
+	}, nil
+}
+
+// DecodeClaimEvent decodes a ClaimEvent log, either the current or the pre
+// etrog one
+func DecodeClaimEvent(log *types.Log) (*ClaimEvent, error) {
+	if len(log.Topics) == 0 {
+		return nil, ErrUnexpectedEvent
+	}
+	switch log.Topics[0] {
+	case ClaimEventTopic:
+		var event struct {
+			GlobalIndex        *big.Int
+			OriginNetwork      uint32
+			OriginAddress      common.Address
+			DestinationAddress common.Address
+			Amount             *big.Int
+		}
+		if err := BridgeABI.UnpackIntoInterface(&event, "ClaimEvent", log.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode ClaimEvent: %w", err)
+		}
+		return &ClaimEvent{
+			LogRef:             newLogRef(log),
+			GlobalIndex:        event.GlobalIndex,
+			OriginNetwork:      event.OriginNetwork,
+			OriginAddress:      event.OriginAddress,
+			DestinationAddress: event.DestinationAddress,
+			Amount:             event.Amount,
+		}, nil
+
+	case LegacyClaimEventTopic:
+		var event struct {
+			Index              uint32
+			OriginNetwork      uint32
+			OriginAddress      common.Address
+			DestinationAddress common.Address
+			Amount             *big.Int
+		}
+		if err := legacyClaimEvents.UnpackIntoInterface(&event, "ClaimEvent", log.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode legacy ClaimEvent: %w", err)
+		}
+		return &ClaimEvent{
+			LogRef:             newLogRef(log),
+			GlobalIndex:        new(big.Int).SetUint64(uint64(event.Index)),
+			OriginNetwork:      event.OriginNetwork,
+			OriginAddress:      event.OriginAddress,
+			DestinationAddress: event.DestinationAddress,
+			Amount:             event.Amount,
+			Legacy:             true,
+		}, nil
+
+	default:
+		return nil, ErrUnexpectedEvent
+	}
+}
+
+// DecodeLogs decodes the bridge events of the logs, ignoring the other
+// ones. When bridgeAddr is set only the logs it emitted are decoded.
+func DecodeLogs(logs []*types.Log, bridgeAddr *common.Address) (*Events, error) {
+	events := &Events{}
+	for _, log := range logs {
+		if len(log.Topics) == 0 || (bridgeAddr != nil && log.Address != *bridgeAddr) {
+			continue
+		}
+		switch log.Topics[0] {
+		case BridgeEventTopic:
+			event, err := DecodeBridgeEvent(log)
+			if err != nil {
+				return nil, fmt.Errorf("log %v of tx %v: %w", log.Index, log.TxHash.String(), err)
+			}
+			events.BridgeEvents = append(events.BridgeEvents, event)
+		case ClaimEventTopic, LegacyClaimEventTopic:
+			event, err := DecodeClaimEvent(log)
+			if err != nil {
+				return nil, fmt.Errorf("log %v of tx %v: %w", log.Index, log.TxHash.String(), err)
+			}
+			events.ClaimEvents = append(events.ClaimEvents, event)
+		}
+	}
+	return events, nil
+}
+
+// DecodeReceipt decodes the bridge events of the receipt, see DecodeLogs
+func DecodeReceipt(receipt *types.Receipt, bridgeAddr *common.Address) (*Events, error) {
+	return DecodeLogs(receipt.Logs, bridgeAddr)
+}
+
+// ReceiptFetcher returns the receipt of a tx, it is implemented by
+// ethclient.Client and the simulated backend client. A nil receipt or an
+// ethereum.NotFound error means the tx is not mined.
+type ReceiptFetcher interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// DecodeTx fetches the receipt of the tx and decodes its bridge events, see
+// DecodeLogs
+func DecodeTx(ctx context.Context, client ReceiptFetcher, txHash common.Hash, bridgeAddr *common.Address) (*Events, error) {
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return nil, fmt.Errorf("failed to get receipt of tx %v: %w", txHash.String(), err)
+	}
+	if receipt == nil {
+		return nil, fmt.Errorf("tx %v: %w", txHash.String(), ErrReceiptNotFound)
+	}
+	return DecodeReceipt(receipt, bridgeAddr)
+}
+
+// DecodeTxAtURL connects to the RPC URL, then fetches the receipt of the tx
+// and decodes its bridge events, see DecodeLogs
+func DecodeTxAtURL(ctx context.Context, rpcURL string, txHash common.Hash, bridgeAddr *common.Address) (*Events, error) {
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %v: %w", rpcURL, err)
+	}
+	defer client.Close()
+	return DecodeTx(ctx, client, txHash, bridgeAddr)
+}
@@ -0,0 +1,165 @@
+package bridgeevents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emitterCode logs its calldata, using the first word as the topic:
+// calldatacopy(0, 0, calldatasize()) log1(32, sub(calldatasize(), 32), mload(0))
+var emitterCode = common.FromHex("0x366000600037600051602036036020a100")
+
+func newLog(t *testing.T, address common.Address, event abi.Event, args ...any) *types.Log {
+	t.Helper()
+	data, err := event.Inputs.Pack(args...)
+	require.NoError(t, err)
+	return &types.Log{Address: address, Topics: []common.Hash{event.ID}, Data: data}
+}
+
+func TestDecodeLogs(t *testing.T) {
+	bridgeAddr := common.HexToAddress("0xb41d9e")
+	token := common.HexToAddress("0x70c3")
+	destination := common.HexToAddress("0xde57")
+	amount, _ := new(big.Int).SetString("1000000000000000000000", 10)                   //nolint:mnd
+	globalIndex := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(3)) //nolint:mnd
+
+	logs := []*types.Log{
+		newLog(t, bridgeAddr, BridgeABI.Events["BridgeEvent"], uint8(0), uint32(0), token, uint32(1), destination, amount, []byte{0x1}, uint32(7)),
+		newLog(t, common.HexToAddress("0x07e5"), BridgeABI.Events["BridgeEvent"], uint8(1), uint32(0), token, uint32(1), destination, amount, []byte{}, uint32(8)),
+		newLog(t, bridgeAddr, BridgeABI.Events["ClaimEvent"], globalIndex, uint32(1), token, destination, amount),
+		newLog(t, bridgeAddr, legacyClaimEvents.Events["ClaimEvent"], uint32(4), uint32(0), common.Address{}, destination, big.NewInt(5)),
+		{Address: bridgeAddr, Topics: []common.Hash{crypto.Keccak256Hash([]byte("Other()"))}},
+	}
+	for i, log := range logs {
+		log.Index = uint(i)
+	}
+
+	events, err := DecodeLogs(logs, &bridgeAddr)
+	require.NoError(t, err)
+	require.Len(t, events.BridgeEvents, 1)
+	require.Len(t, events.ClaimEvents, 2) //nolint:mnd
+
+	bridge := events.BridgeEvents[0]
+	assert.Equal(t, bridgeservice.LeafTypeAsset, bridge.LeafType)
+	assert.Equal(t, token, bridge.OriginAddress)
+	assert.Equal(t, uint32(1), bridge.DestinationNetwork)
+	assert.Equal(t, destination, bridge.DestinationAddress)
+	assert.Equal(t, amount, bridge.Amount)
+	assert.Equal(t, []byte{0x1}, []byte(bridge.Metadata))
+	assert.Equal(t, uint32(7), bridge.DepositCount)
+
+	claim := events.ClaimEvents[0]
+	assert.Equal(t, globalIndex, claim.GlobalIndex)
+	assert.Equal(t, uint32(1), claim.OriginNetwork)
+	assert.Equal(t, uint(2), claim.LogIndex)
+	assert.False(t, claim.Legacy)
+
+	legacy := events.ClaimEvents[1]
+	assert.Equal(t, int64(4), legacy.GlobalIndex.Int64())
+	assert.Equal(t, int64(5), legacy.Amount.Int64())
+	assert.True(t, legacy.Legacy)
+
+	all, err := DecodeLogs(logs, nil)
+	require.NoError(t, err)
+	require.Len(t, all.BridgeEvents, 2) //nolint:mnd
+	assert.Equal(t, bridgeservice.LeafTypeMessage, all.BridgeEvents[1].LeafType)
+
+	encoded, err := json.Marshal(bridge)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"depositCount":7`)
+	assert.Contains(t, string(encoded), `"amount":"1000000000000000000000"`)
+	assert.Contains(t, string(encoded), `"metadata":"0x01"`)
+	assert.Contains(t, string(encoded), `"txHash":`)
+	var decodedBridge BridgeEvent
+	require.NoError(t, json.Unmarshal(encoded, &decodedBridge))
+	assert.Equal(t, 0, amount.Cmp(decodedBridge.Amount))
+	assert.Equal(t, bridge.LogRef, decodedBridge.LogRef)
+
+	encoded, err = json.Marshal(claim)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"globalIndex":"18446744073709551619"`)
+	assert.Contains(t, string(encoded), `"amount":"1000000000000000000000"`)
+	var decodedClaim ClaimEvent
+	require.NoError(t, json.Unmarshal(encoded, &decodedClaim))
+	assert.Equal(t, 0, globalIndex.Cmp(decodedClaim.GlobalIndex))
+	assert.Equal(t, claim.OriginAddress, decodedClaim.OriginAddress)
+
+	_, err = DecodeBridgeEvent(logs[2])
+	require.ErrorIs(t, err, ErrUnexpectedEvent)
+	_, err = DecodeClaimEvent(logs[0])
+	require.ErrorIs(t, err, ErrUnexpectedEvent)
+
+	logs[0].Data = logs[0].Data[:32]
+	_, err = DecodeLogs(logs, &bridgeAddr)
+	require.Error(t, err)
+}
+
+// receiptFetcherFunc adapts a function to ReceiptFetcher
+type receiptFetcherFunc func(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+
+func (f receiptFetcherFunc) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return f(ctx, txHash)
+}
+
+func TestDecodeTxNotMined(t *testing.T) {
+	ctx := context.Background()
+
+	for _, err := range []error{nil, ethereum.NotFound} {
+		fetcher := receiptFetcherFunc(func(context.Context, common.Hash) (*types.Receipt, error) { return nil, err })
+		_, decodeErr := DecodeTx(ctx, fetcher, common.HexToHash("0x1"), nil)
+		require.ErrorIs(t, decodeErr, ErrReceiptNotFound)
+	}
+
+	failure := errors.New("connection refused")
+	fetcher := receiptFetcherFunc(func(context.Context, common.Hash) (*types.Receipt, error) { return nil, failure })
+	_, err := DecodeTx(ctx, fetcher, common.HexToHash("0x1"), nil)
+	require.ErrorIs(t, err, failure)
+}
+
+func TestDecodeTx(t *testing.T) {
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337)) //nolint:mnd
+	require.NoError(t, err)
+
+	bridgeAddr := common.HexToAddress("0xb41d9e")
+	balance, _ := new(big.Int).SetString("1000000000000000000000", 10) //nolint:mnd
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		auth.From:  {Balance: balance},
+		bridgeAddr: {Code: emitterCode},
+	})
+	t.Cleanup(func() { _ = backend.Close() })
+
+	event := BridgeABI.Events["BridgeEvent"]
+	data, err := event.Inputs.Pack(uint8(0), uint32(0), common.Address{}, uint32(1), auth.From, big.NewInt(100), []byte{}, uint32(0))
+	require.NoError(t, err)
+	contract := bind.NewBoundContract(bridgeAddr, abi.ABI{}, backend.Client(), backend.Client(), backend.Client())
+	tx, err := contract.RawTransact(auth, append(event.ID.Bytes(), data...))
+	require.NoError(t, err)
+
+	backend.Commit()
+
+	events, err := DecodeTx(ctx, backend.Client(), tx.Hash(), &bridgeAddr)
+	require.NoError(t, err)
+	require.Len(t, events.BridgeEvents, 1)
+	assert.Empty(t, events.ClaimEvents)
+	assert.Equal(t, tx.Hash(), events.BridgeEvents[0].TxHash)
+	assert.Equal(t, bridgeAddr, events.BridgeEvents[0].Address)
+	assert.Equal(t, int64(100), events.BridgeEvents[0].Amount.Int64())
+}
@@ -0,0 +1,301 @@
+package bridgeservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/agglayer/e2e/core/golang/tools/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// APIError is returned when the service answers with a non 200 status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the error message.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v - %v", e.StatusCode, e.Message)
+}
+
+// NotFound reports whether the requested item is not indexed yet
+func (e *APIError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// Retryable reports whether the request may succeed later: the item is not
+// indexed yet or the service failed, while the other 4xx statuses reject
+// the request itself
+func (e *APIError) Retryable() bool {
+	return e.NotFound() || e.StatusCode >= http.StatusInternalServerError
+}
+
+// Client is a typed client for the REST API of the aggkit bridge service
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the http.Client used to send the requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient creates a bridge service client for the provided base URL
+func NewClient(url string, opts ...ClientOption) *Client {
+	c := &Client{
+		url:        strings.TrimSuffix(url, "/"),
+		httpClient: &http.Client{Timeout: engine.DefaultRequestTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// URL returns the base URL of the service
+func (c *Client) URL() string {
+	return c.url
+}
+
+// Page selects a page of a paginated endpoint, zero values use the service
+// defaults
+type Page struct {
+	Number uint32
+	Size   uint32
+}
+
+func (p Page) apply(values url.Values) {
+	if p.Number != 0 {
+		values.Set("page_number", strconv.FormatUint(uint64(p.Number), 10))
+	}
+	if p.Size != 0 {
+		values.Set("page_size", strconv.FormatUint(uint64(p.Size), 10))
+	}
+}
+
+// BridgesFilter narrows the bridges returned by the service
+type BridgesFilter struct {
+	DepositCount *uint32
+	FromAddress  *common.Address
+	Page         Page
+}
+
+// ClaimsFilter narrows the claims returned by the service
+type ClaimsFilter struct {
+	GlobalIndex *big.Int
+	FromAddress *common.Address
+	// IncludeAllFields requests the proofs and exit roots of the claims
+	IncludeAllFields bool
+	Page             Page
+}
+
+// Bridges returns a page of the bridges of the network, newest first
+func (c *Client) Bridges(t *testing.T, ctx context.Context, networkID uint32, filter BridgesFilter) (*BridgesResult, error) {
+	values := networkValues(networkID)
+	if filter.DepositCount != nil {
+		values.Set("deposit_count", strconv.FormatUint(uint64(*filter.DepositCount), 10))
+	}
+	if filter.FromAddress != nil {
+		values.Set("from_address", filter.FromAddress.String())
+	}
+	filter.Page.apply(values)
+	return get[*BridgesResult](t, ctx, c, BridgesPath, values)
+}
+
+// AllBridges returns every bridge of the network matching the filter, newest
+// first, fetching as many pages as needed. The filter page is ignored.
+func (c *Client) AllBridges(t *testing.T, ctx context.Context, networkID uint32, filter BridgesFilter) ([]*Bridge, error) {
+	return allPages(func(page Page) ([]*Bridge, int, error) {
+		filter.Page = page
+		result, err := c.Bridges(t, ctx, networkID, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		return result.Bridges, result.Count, nil
+	})
+}
+
+// Claims returns a page of the claims of the network, newest first
+func (c *Client) Claims(t *testing.T, ctx context.Context, networkID uint32, filter ClaimsFilter) (*ClaimsResult, error) {
+	values := networkValues(networkID)
+	if filter.GlobalIndex != nil {
+		values.Set("global_index", filter.GlobalIndex.String())
+	}
+	if filter.FromAddress != nil {
+		values.Set("from_address", filter.FromAddress.String())
+	}
+	if filter.IncludeAllFields {
+		values.Set("include_all_fields", "true")
+	}
+	filter.Page.apply(values)
+	return get[*ClaimsResult](t, ctx, c, ClaimsPath, values)
+}
+
+// AllClaims returns every claim of the network matching the filter, newest
+// first, fetching as many pages as needed. The filter page is ignored.
+func (c *Client) AllClaims(t *testing.T, ctx context.Context, networkID uint32, filter ClaimsFilter) ([]*Claim, error) {
+	return allPages(func(page Page) ([]*Claim, int, error) {
+		filter.Page = page
+		result, err := c.Claims(t, ctx, networkID, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		return result.Claims, result.Count, nil
+	})
+}
+
+// ClaimProof returns the proofs needed to claim the bridge with the deposit
+// count using the L1 info tree leaf at leafIndex
+func (c *Client) ClaimProof(t *testing.T, ctx context.Context, networkID, depositCount, leafIndex uint32) (*ClaimProof, error) {
+	values := networkValues(networkID)
+	values.Set("deposit_count", strconv.FormatUint(uint64(depositCount), 10))
+	values.Set("leaf_index", strconv.FormatUint(uint64(leafIndex), 10))
+	return get[*ClaimProof](t, ctx, c, ClaimProofPath, values)
+}
+
+// L1InfoTreeIndex returns the index of the first L1 info tree leaf including
+// the bridge with the deposit count
+func (c *Client) L1InfoTreeIndex(t *testing.T, ctx context.Context, networkID, depositCount uint32) (uint32, error) {
+	values := networkValues(networkID)
+	values.Set("deposit_count", strconv.FormatUint(uint64(depositCount), 10))
+	return get[uint32](t, ctx, c, L1InfoTreeIndexPath, values)
+}
+
+// InjectedL1InfoLeaf returns the L1 info tree leaf at leafIndex once its
+// global exit root is injected in the network
+func (c *Client) InjectedL1InfoLeaf(t *testing.T, ctx context.Context, networkID, leafIndex uint32) (*L1InfoTreeLeaf, error) {
+	values := networkValues(networkID)
+	values.Set("leaf_index", strconv.FormatUint(uint64(leafIndex), 10))
+	return get[*L1InfoTreeLeaf](t, ctx, c, InjectedL1InfoLeafPath, values)
+}
+
+// TokenMappings returns a page of the token mappings of the network
+func (c *Client) TokenMappings(t *testing.T, ctx context.Context, networkID uint32, page Page) (*TokenMappingsResult, error) {
+	values := networkValues(networkID)
+	page.apply(values)
+	return get[*TokenMappingsResult](t, ctx, c, TokenMappingsPath, values)
+}
+
+// LegacyTokenMigrations returns a page of the legacy token migrations of the
+// network
+func (c *Client) LegacyTokenMigrations(t *testing.T, ctx context.Context, networkID uint32, page Page) (*LegacyTokenMigrationsResult, error) {
+	values := networkValues(networkID)
+	page.apply(values)
+	return get[*LegacyTokenMigrationsResult](t, ctx, c, LegacyTokenMigrationsPath, values)
+}
+
+// RemovedGERs returns up to limit removed global exit roots, optionally only
+// the given one, a zero limit uses the service default
+func (c *Client) RemovedGERs(t *testing.T, ctx context.Context, globalExitRoot *common.Hash, limit uint32) (*RemovedGERsResult, error) {
+	values := url.Values{}
+	if globalExitRoot != nil {
+		values.Set("global_exit_root", globalExitRoot.String())
+	}
+	if limit != 0 {
+		values.Set("limit", strconv.FormatUint(uint64(limit), 10))
+	}
+	return get[*RemovedGERsResult](t, ctx, c, RemovedGERsPath, values)
+}
+
+// SetClaims returns a page of the set claims, optionally only the ones of
+// the global index
+func (c *Client) SetClaims(t *testing.T, ctx context.Context, globalIndex *big.Int, page Page) (*SetClaimsResult, error) {
+	values := url.Values{}
+	if globalIndex != nil {
+		values.Set("global_index", globalIndex.String())
+	}
+	page.apply(values)
+	return get[*SetClaimsResult](t, ctx, c, SetClaimsPath, values)
+}
+
+// UnsetClaims returns a page of the unset claims, optionally only the ones of
+// the global index
+func (c *Client) UnsetClaims(t *testing.T, ctx context.Context, globalIndex *big.Int, page Page) (*UnsetClaimsResult, error) {
+	values := url.Values{}
+	if globalIndex != nil {
+		values.Set("global_index", globalIndex.String())
+	}
+	page.apply(values)
+	return get[*UnsetClaimsResult](t, ctx, c, UnsetClaimsPath, values)
+}
+
+func networkValues(networkID uint32) url.Values {
+	values := url.Values{}
+	values.Set("network_id", strconv.FormatUint(uint64(networkID), 10))
+	return values
+}
+
+// get sends a GET request to the path and decodes the JSON body into T
+func get[T any](t *testing.T, ctx context.Context, c *Client, path string, values url.Values) (T, error) {
+	var result T
+
+	endpoint := c.url + path
+	if len(values) > 0 {
+		endpoint += "?" + values.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return result, err
+	}
+	log.Msgf(t, "bridge service call to %v", endpoint)
+	log.Complements(t, fmt.Sprintf("status: %v, took %v", res.StatusCode, time.Since(start)), fmt.Sprintf("response: %v", strings.TrimSpace(string(body))))
+
+	if res.StatusCode != http.StatusOK {
+		var errRes ErrorResponse
+		if json.Unmarshal(body, &errRes) != nil || errRes.Error == "" {
+			errRes.Error = strings.TrimSpace(string(body))
+		}
+		return result, &APIError{StatusCode: res.StatusCode, Message: errRes.Error}
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return result, fmt.Errorf("failed to decode response of %v: %w", path, err)
+	}
+	return result, nil
+}
+
+// allPages fetches pages of the biggest size until count items are collected
+// or an empty page is returned
+func allPages[T any](fetch func(page Page) ([]T, int, error)) ([]T, error) {
+	var items []T
+	for number := uint32(1); ; number++ {
+		page, count, err := fetch(Page{Number: number, Size: MaxPageSize})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if len(page) == 0 || len(items) >= count {
+			return items, nil
+		}
+	}
+}
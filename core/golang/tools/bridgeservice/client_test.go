@@ -0,0 +1,191 @@
+package bridgeservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer serves the handlers by path, encoding their result as JSON
+// with the returned status
+func newTestServer(t *testing.T, handlers map[string]func(query url.Values) (any, int)) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, found := handlers[r.URL.Path]
+		require.Truef(t, found, "unexpected path %v", r.URL.Path)
+		result, status := handler(r.URL.Query())
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func setPollInterval(t *testing.T, interval time.Duration) {
+	previous := PollInterval
+	PollInterval = interval
+	t.Cleanup(func() { PollInterval = previous })
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	from := common.HexToAddress("0xf00")
+
+	// 5 bridges served newest first, at most 2 per page whatever the size
+	bridges := make([]*Bridge, 5) //nolint:mnd
+	for i := range bridges {
+		bridges[i] = &Bridge{DepositCount: uint32(len(bridges) - 1 - i), Amount: NewBigIntString(big.NewInt(int64(i)))}
+	}
+	server := newTestServer(t, map[string]func(url.Values) (any, int){
+		BridgesPath: func(query url.Values) (any, int) {
+			assert.Equal(t, "1", query.Get("network_id"))
+			assert.Equal(t, from.String(), query.Get("from_address"))
+			number, err := strconv.Atoi(query.Get("page_number"))
+			require.NoError(t, err)
+			start, end := min(2*(number-1), len(bridges)), min(2*number, len(bridges))
+			return BridgesResult{Bridges: bridges[start:end], Count: len(bridges)}, http.StatusOK
+		},
+		ClaimsPath: func(query url.Values) (any, int) {
+			assert.Equal(t, "true", query.Get("include_all_fields"))
+			assert.Equal(t, "18446744073709551617", query.Get("global_index"))
+			return ClaimsResult{Claims: []*Claim{{GlobalIndex: NewBigIntString(new(big.Int).Lsh(big.NewInt(1), 64))}}, Count: 1}, http.StatusOK
+		},
+		L1InfoTreeIndexPath: func(query url.Values) (any, int) {
+			if query.Get("deposit_count") == "7" {
+				return 3, http.StatusOK
+			}
+			return ErrorResponse{Error: "not found"}, http.StatusNotFound
+		},
+		ClaimProofPath: func(query url.Values) (any, int) {
+			assert.Equal(t, "7", query.Get("deposit_count"))
+			assert.Equal(t, "3", query.Get("leaf_index"))
+			return ClaimProof{L1InfoTreeLeaf: L1InfoTreeLeaf{L1InfoTreeIndex: 3}}, http.StatusOK
+		},
+	})
+	client := NewClient(server.URL + "/")
+
+	all, err := client.AllBridges(t, ctx, 1, BridgesFilter{FromAddress: &from})
+	require.NoError(t, err)
+	require.Len(t, all, len(bridges))
+	for i, bridge := range all {
+		assert.Equal(t, bridges[i].DepositCount, bridge.DepositCount)
+	}
+
+	globalIndex := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))
+	claims, err := client.Claims(t, ctx, 1, ClaimsFilter{GlobalIndex: globalIndex, IncludeAllFields: true})
+	require.NoError(t, err)
+	require.Len(t, claims.Claims, 1)
+
+	index, err := client.L1InfoTreeIndex(t, ctx, 1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), index)
+
+	_, err = client.L1InfoTreeIndex(t, ctx, 1, 8)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.True(t, apiErr.NotFound())
+	assert.Equal(t, "not found", apiErr.Message)
+
+	proof, err := client.ClaimProof(t, ctx, 1, 7, index)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), proof.L1InfoTreeLeaf.L1InfoTreeIndex)
+}
+
+func TestWaitBridge(t *testing.T) {
+	setPollInterval(t, 10*time.Millisecond)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	server := newTestServer(t, map[string]func(url.Values) (any, int){
+		BridgesPath: func(query url.Values) (any, int) {
+			assert.Equal(t, "4", query.Get("deposit_count"))
+			switch calls.Add(1) {
+			case 1:
+				return ErrorResponse{Error: "syncing"}, http.StatusInternalServerError
+			case 2: //nolint:mnd
+				return BridgesResult{}, http.StatusOK
+			default:
+				return BridgesResult{Bridges: []*Bridge{{DepositCount: 4}}, Count: 1}, http.StatusOK
+			}
+		},
+	})
+	client := NewClient(server.URL)
+
+	bridge, err := WaitBridge(t, ctx, client, 0, 4, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(4), bridge.DepositCount)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestWaitBridgeErrors(t *testing.T) {
+	setPollInterval(t, 10*time.Millisecond)
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	server := newTestServer(t, map[string]func(url.Values) (any, int){
+		BridgesPath: func(query url.Values) (any, int) {
+			calls.Add(1)
+			return ErrorResponse{Error: "invalid network id"}, http.StatusBadRequest
+		},
+	})
+	_, err := WaitBridge(t, ctx, NewClient(server.URL), 9, 4, time.Second)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// the first connection is dropped and the item is not indexed on the second call
+	calls.Store(0)
+	dropping := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			_ = conn.Close()
+		case 2: //nolint:mnd
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(ErrorResponse{Error: "not found"})
+		default:
+			_ = json.NewEncoder(w).Encode(BridgesResult{Bridges: []*Bridge{{DepositCount: 4}}, Count: 1})
+		}
+	}))
+	t.Cleanup(dropping.Close)
+
+	bridge, err := WaitBridge(t, ctx, NewClient(dropping.URL), 0, 4, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(4), bridge.DepositCount)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestWaitUnsetClaim(t *testing.T) {
+	setPollInterval(t, 10*time.Millisecond)
+	ctx := context.Background()
+
+	server := newTestServer(t, map[string]func(url.Values) (any, int){
+		UnsetClaimsPath: func(query url.Values) (any, int) {
+			return UnsetClaimsResult{UnsetClaims: []*UnsetClaim{{GlobalIndex: NewBigIntString(big.NewInt(1))}}, Count: 1}, http.StatusOK
+		},
+	})
+	client := NewClient(server.URL)
+
+	_, err := WaitUnsetClaim(t, ctx, client, big.NewInt(2), 100*time.Millisecond)
+	var timeoutErr *engine.WaitTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	claim, err := WaitUnsetClaim(t, ctx, client, big.NewInt(1), time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), claim.GlobalIndex.Int64())
+}
@@ -0,0 +1,233 @@
+package bridgeservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Paths of the aggkit bridge service REST API
+const (
+	BridgesPath               = "/bridge/v1/bridges"
+	ClaimsPath                = "/bridge/v1/claims"
+	ClaimProofPath            = "/bridge/v1/claim-proof"
+	L1InfoTreeIndexPath       = "/bridge/v1/l1-info-tree-index"
+	InjectedL1InfoLeafPath    = "/bridge/v1/injected-l1-info-leaf"
+	TokenMappingsPath         = "/bridge/v1/token-mappings"
+	LegacyTokenMigrationsPath = "/bridge/v1/legacy-token-migrations"
+	RemovedGERsPath           = "/bridge/v1/removed-gers"
+	SetClaimsPath             = "/bridge/v1/set-claims"
+	UnsetClaimsPath           = "/bridge/v1/unset-claims"
+)
+
+const (
+	// DefaultPageSize is the page size used when the request doesn't set one
+	DefaultPageSize = 20
+	// MaxPageSize is the biggest page size accepted by the service
+	MaxPageSize = 1000
+)
+
+// LeafType is the type of a bridge, asset or message
+type LeafType uint8
+
+const (
+	LeafTypeAsset   LeafType = 0
+	LeafTypeMessage LeafType = 1
+)
+
+// Proof is a merkle proof of a 32 levels exit tree
+type Proof [32]common.Hash
+
+// BigIntString is a big integer encoded as a decimal string, numbers are
+// accepted when decoding
+type BigIntString struct {
+	*big.Int
+}
+
+// NewBigIntString wraps the value, nil is handled as zero
+func NewBigIntString(v *big.Int) *BigIntString {
+	if v == nil {
+		v = new(big.Int)
+	}
+	return &BigIntString{Int: new(big.Int).Set(v)}
+}
+
+// MarshalJSON encodes the value as a decimal string
+func (b BigIntString) MarshalJSON() ([]byte, error) {
+	if b.Int == nil {
+		return json.Marshal("0")
+	}
+	return json.Marshal(b.Int.String())
+}
+
+// UnmarshalJSON decodes decimal strings and numbers
+func (b *BigIntString) UnmarshalJSON(input []byte) error {
+	str := strings.Trim(string(input), `"`)
+	v, ok := new(big.Int).SetString(str, 10) //nolint:mnd
+	if !ok {
+		return fmt.Errorf("invalid big integer %v", string(input))
+	}
+	b.Int = v
+	return nil
+}
+
+// Bridge is a bridge indexed by the service
+type Bridge struct {
+	BlockNum           uint64         `json:"block_num"`
+	BlockPos           uint64         `json:"block_pos"`
+	BlockTimestamp     uint64         `json:"block_timestamp"`
+	TxHash             common.Hash    `json:"tx_hash"`
+	FromAddress        common.Address `json:"from_address"`
+	Calldata           hexutil.Bytes  `json:"calldata,omitempty"`
+	LeafType           LeafType       `json:"leaf_type"`
+	OriginNetwork      uint32         `json:"origin_network"`
+	OriginAddress      common.Address `json:"origin_address"`
+	DestinationNetwork uint32         `json:"destination_network"`
+	DestinationAddress common.Address `json:"destination_address"`
+	Amount             *BigIntString  `json:"amount"`
+	Metadata           hexutil.Bytes  `json:"metadata"`
+	DepositCount       uint32         `json:"deposit_count"`
+	IsNativeToken      bool           `json:"is_native_token"`
+	BridgeHash         common.Hash    `json:"bridge_hash"`
+}
+
+// BridgesResult is a page of bridges
+type BridgesResult struct {
+	Bridges []*Bridge `json:"bridges"`
+	Count   int       `json:"count"`
+}
+
+// Claim is a claim indexed by the service
+type Claim struct {
+	BlockNum            uint64         `json:"block_num"`
+	BlockTimestamp      uint64         `json:"block_timestamp"`
+	TxHash              common.Hash    `json:"tx_hash"`
+	GlobalIndex         *BigIntString  `json:"global_index"`
+	OriginNetwork       uint32         `json:"origin_network"`
+	OriginAddress       common.Address `json:"origin_address"`
+	DestinationNetwork  uint32         `json:"destination_network"`
+	DestinationAddress  common.Address `json:"destination_address"`
+	Amount              *BigIntString  `json:"amount"`
+	FromAddress         common.Address `json:"from_address"`
+	GlobalExitRoot      common.Hash    `json:"global_exit_root"`
+	MainnetExitRoot     common.Hash    `json:"mainnet_exit_root"`
+	RollupExitRoot      common.Hash    `json:"rollup_exit_root"`
+	Metadata            hexutil.Bytes  `json:"metadata"`
+	ProofLocalExitRoot  *Proof         `json:"proof_local_exit_root,omitempty"`
+	ProofRollupExitRoot *Proof         `json:"proof_rollup_exit_root,omitempty"`
+}
+
+// ClaimsResult is a page of claims
+type ClaimsResult struct {
+	Claims []*Claim `json:"claims"`
+	Count  int      `json:"count"`
+}
+
+// L1InfoTreeLeaf is a leaf of the L1 info tree
+type L1InfoTreeLeaf struct {
+	BlockNum          uint64      `json:"block_num"`
+	BlockPos          uint64      `json:"block_pos"`
+	L1InfoTreeIndex   uint32      `json:"l1_info_tree_index"`
+	PreviousBlockHash common.Hash `json:"previous_block_hash"`
+	Timestamp         uint64      `json:"timestamp"`
+	MainnetExitRoot   common.Hash `json:"mainnet_exit_root"`
+	RollupExitRoot    common.Hash `json:"rollup_exit_root"`
+	GlobalExitRoot    common.Hash `json:"global_exit_root"`
+	Hash              common.Hash `json:"hash"`
+}
+
+// ClaimProof is the data needed to claim a bridge on the destination network
+type ClaimProof struct {
+	ProofLocalExitRoot  Proof          `json:"proof_local_exit_root"`
+	ProofRollupExitRoot Proof          `json:"proof_rollup_exit_root"`
+	L1InfoTreeLeaf      L1InfoTreeLeaf `json:"l1_info_tree_leaf"`
+}
+
+// TokenMapping links a token of another network with its wrapped token
+type TokenMapping struct {
+	BlockNum            uint64         `json:"block_num"`
+	BlockPos            uint64         `json:"block_pos"`
+	BlockTimestamp      uint64         `json:"block_timestamp"`
+	TxHash              common.Hash    `json:"tx_hash"`
+	OriginNetwork       uint32         `json:"origin_network"`
+	OriginTokenAddress  common.Address `json:"origin_token_address"`
+	WrappedTokenAddress common.Address `json:"wrapped_token_address"`
+	Metadata            hexutil.Bytes  `json:"metadata"`
+}
+
+// TokenMappingsResult is a page of token mappings
+type TokenMappingsResult struct {
+	TokenMappings []*TokenMapping `json:"token_mappings"`
+	Count         int             `json:"count"`
+}
+
+// LegacyTokenMigration is a migration from a legacy wrapped token to the
+// updated one
+type LegacyTokenMigration struct {
+	BlockNum            uint64         `json:"block_num"`
+	BlockPos            uint64         `json:"block_pos"`
+	BlockTimestamp      uint64         `json:"block_timestamp"`
+	TxHash              common.Hash    `json:"tx_hash"`
+	Sender              common.Address `json:"sender"`
+	LegacyTokenAddress  common.Address `json:"legacy_token_address"`
+	UpdatedTokenAddress common.Address `json:"updated_token_address"`
+	Amount              *BigIntString  `json:"amount"`
+	Calldata            hexutil.Bytes  `json:"calldata,omitempty"`
+}
+
+// LegacyTokenMigrationsResult is a page of legacy token migrations
+type LegacyTokenMigrationsResult struct {
+	LegacyTokenMigrations []*LegacyTokenMigration `json:"legacy_token_migrations"`
+	Count                 int                     `json:"count"`
+}
+
+// RemovedGER is a global exit root removed from a sovereign chain
+type RemovedGER struct {
+	BlockNum       uint64      `json:"block_num"`
+	BlockPos       uint64      `json:"block_pos"`
+	GlobalExitRoot common.Hash `json:"global_exit_root"`
+}
+
+// RemovedGERsResult is the list of removed global exit roots
+type RemovedGERsResult struct {
+	RemovedGERs []*RemovedGER `json:"removed_gers"`
+	Count       int           `json:"count"`
+}
+
+// SetClaim is a claim marked as done by a sovereign chain admin
+type SetClaim struct {
+	BlockNum    uint64        `json:"block_num"`
+	BlockPos    uint64        `json:"block_pos"`
+	TxHash      common.Hash   `json:"tx_hash"`
+	GlobalIndex *BigIntString `json:"global_index"`
+}
+
+// SetClaimsResult is a page of set claims
+type SetClaimsResult struct {
+	SetClaims []*SetClaim `json:"set_claims"`
+	Count     int         `json:"count"`
+}
+
+// UnsetClaim is a claim reverted by a sovereign chain admin
+type UnsetClaim struct {
+	BlockNum                  uint64        `json:"block_num"`
+	BlockPos                  uint64        `json:"block_pos"`
+	TxHash                    common.Hash   `json:"tx_hash"`
+	GlobalIndex               *BigIntString `json:"global_index"`
+	UnsetGlobalIndexHashChain common.Hash   `json:"unset_global_index_hash_chain"`
+}
+
+// UnsetClaimsResult is a page of unset claims
+type UnsetClaimsResult struct {
+	UnsetClaims []*UnsetClaim `json:"unset_claims"`
+	Count       int           `json:"count"`
+}
+
+// ErrorResponse is the body returned by the service when a request fails
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
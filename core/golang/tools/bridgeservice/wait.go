@@ -0,0 +1,167 @@
+package bridgeservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TimeoutToBeIndexed is the usual time for the service to index a bridge,
+// a claim or an L1 info tree update
+const TimeoutToBeIndexed = 5 * time.Minute
+
+// PollInterval is the time between two checks of the lookup waits
+var PollInterval = 3 * time.Second
+
+// notReady turns the errors worth retrying into NotReady ones: the 404 the
+// service answers while the requested item is not indexed yet, the 5xx and
+// the transport errors. Any other error, like a 400 rejecting the params,
+// stops the wait.
+func notReady(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Retryable() {
+			return engine.NotReady("%v", apiErr.Error())
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return engine.NotReady("%v", err.Error())
+	}
+	return err
+}
+
+// wait polls lookup until it returns a result
+func wait[T any](t *testing.T, ctx context.Context, description string, timeout time.Duration, lookup func(ctx context.Context) (T, bool, error)) (T, error) {
+	var result T
+	err := engine.WaitFor(t, ctx, description, engine.Every(PollInterval), timeout, func(ctx context.Context) (bool, error) {
+		found, ok, err := lookup(ctx)
+		if err != nil {
+			return false, notReady(err)
+		}
+		if ok {
+			result = found
+		}
+		return ok, nil
+	})
+	return result, err
+}
+
+// WaitBridge waits until the bridge with the deposit count is indexed on
+// the network
+func WaitBridge(t *testing.T, ctx context.Context, client *Client, networkID, depositCount uint32, timeout time.Duration) (*Bridge, error) {
+	description := fmt.Sprintf("bridge with deposit count %v on network %v", depositCount, networkID)
+	return wait(t, ctx, description, timeout, func(ctx context.Context) (*Bridge, bool, error) {
+		result, err := client.Bridges(t, ctx, networkID, BridgesFilter{DepositCount: &depositCount})
+		if err != nil || len(result.Bridges) == 0 {
+			return nil, false, err
+		}
+		return result.Bridges[0], true, nil
+	})
+}
+
+// WaitBridgeByTxHash waits until the bridge sent in the tx is indexed on the
+// network. Only the latest bridges are checked, optionally only the ones
+// sent from fromAddress.
+func WaitBridgeByTxHash(t *testing.T, ctx context.Context, client *Client, networkID uint32, txHash common.Hash, fromAddress *common.Address, timeout time.Duration) (*Bridge, error) {
+	description := fmt.Sprintf("bridge of tx %v on network %v", txHash.String(), networkID)
+	return wait(t, ctx, description, timeout, func(ctx context.Context) (*Bridge, bool, error) {
+		result, err := client.Bridges(t, ctx, networkID, BridgesFilter{FromAddress: fromAddress, Page: Page{Size: MaxPageSize}})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, bridge := range result.Bridges {
+			if bridge.TxHash == txHash {
+				return bridge, true, nil
+			}
+		}
+		return nil, false, nil
+	})
+}
+
+// WaitClaim waits until the claim of the global index is indexed on the
+// network, the claim is returned with all its fields
+func WaitClaim(t *testing.T, ctx context.Context, client *Client, networkID uint32, globalIndex *big.Int, timeout time.Duration) (*Claim, error) {
+	description := fmt.Sprintf("claim with global index %v on network %v", globalIndex, networkID)
+	return wait(t, ctx, description, timeout, func(ctx context.Context) (*Claim, bool, error) {
+		result, err := client.Claims(t, ctx, networkID, ClaimsFilter{GlobalIndex: globalIndex, IncludeAllFields: true})
+		if err != nil || len(result.Claims) == 0 {
+			return nil, false, err
+		}
+		return result.Claims[0], true, nil
+	})
+}
+
+// WaitClaimProof waits until the service can build the proofs of the bridge
+// with the deposit count for the L1 info tree leaf at leafIndex
+func WaitClaimProof(t *testing.T, ctx context.Context, client *Client, networkID, depositCount, leafIndex uint32, timeout time.Duration) (*ClaimProof, error) {
+	description := fmt.Sprintf("claim proof of deposit count %v on network %v at L1 info tree index %v", depositCount, networkID, leafIndex)
+	return wait(t, ctx, description, timeout, func(ctx context.Context) (*ClaimProof, bool, error) {
+		proof, err := client.ClaimProof(t, ctx, networkID, depositCount, leafIndex)
+		return proof, err == nil, err
+	})
+}
+
+// WaitL1InfoTreeIndex waits until an L1 info tree leaf includes the bridge
+// with the deposit count and returns its index
+func WaitL1InfoTreeIndex(t *testing.T, ctx context.Context, client *Client, networkID, depositCount uint32, timeout time.Duration) (uint32, error) {
+	description := fmt.Sprintf("L1 info tree index of deposit count %v on network %v", depositCount, networkID)
+	return wait(t, ctx, description, timeout, func(ctx context.Context) (uint32, bool, error) {
+		index, err := client.L1InfoTreeIndex(t, ctx, networkID, depositCount)
+		return index, err == nil, err
+	})
+}
+
+// WaitInjectedL1InfoLeaf waits until the L1 info tree leaf at leafIndex is
+// injected in the network
+func WaitInjectedL1InfoLeaf(t *testing.T, ctx context.Context, client *Client, networkID, leafIndex uint32, timeout time.Duration) (*L1InfoTreeLeaf, error) {
+	description := fmt.Sprintf("injected L1 info tree leaf %v on network %v", leafIndex, networkID)
+	return wait(t, ctx, description, timeout, func(ctx context.Context) (*L1InfoTreeLeaf, bool, error) {
+		leaf, err := client.InjectedL1InfoLeaf(t, ctx, networkID, leafIndex)
+		return leaf, err == nil, err
+	})
+}
+
+// WaitSetClaim waits until the global index is reported as set
+func WaitSetClaim(t *testing.T, ctx context.Context, client *Client, globalIndex *big.Int, timeout time.Duration) (*SetClaim, error) {
+	description := fmt.Sprintf("set claim with global index %v", globalIndex)
+	return wait(t, ctx, description, timeout, func(ctx context.Context) (*SetClaim, bool, error) {
+		result, err := client.SetClaims(t, ctx, globalIndex, Page{})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, claim := range result.SetClaims {
+			if claim.GlobalIndex != nil && claim.GlobalIndex.Cmp(globalIndex) == 0 {
+				return claim, true, nil
+			}
+		}
+		return nil, false, nil
+	})
+}
+
+// WaitUnsetClaim waits until the global index is reported as unset
+func WaitUnsetClaim(t *testing.T, ctx context.Context, client *Client, globalIndex *big.Int, timeout time.Duration) (*UnsetClaim, error) {
+	description := fmt.Sprintf("unset claim with global index %v", globalIndex)
+	return wait(t, ctx, description, timeout, func(ctx context.Context) (*UnsetClaim, bool, error) {
+		result, err := client.UnsetClaims(t, ctx, globalIndex, Page{})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, claim := range result.UnsetClaims {
+			if claim.GlobalIndex != nil && claim.GlobalIndex.Cmp(globalIndex) == 0 {
+				return claim, true, nil
+			}
+		}
+		return nil, false, nil
+	})
+}
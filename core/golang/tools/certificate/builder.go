@@ -0,0 +1,231 @@
+package certificate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MerkleProof is the proof of a leaf along with the root it leads to
+type MerkleProof struct {
+	Root  common.Hash
+	Proof merkletree.Proof
+}
+
+type merkleProofJSON struct {
+	Root  common.Hash `json:"root"`
+	Proof struct {
+		Siblings merkletree.Proof `json:"siblings"`
+	} `json:"proof"`
+}
+
+// MarshalJSON encodes the proof as expected by the agglayer
+func (m MerkleProof) MarshalJSON() ([]byte, error) {
+	var enc merkleProofJSON
+	enc.Root = m.Root
+	enc.Proof.Siblings = m.Proof
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON decodes a proof encoded by MarshalJSON
+func (m *MerkleProof) UnmarshalJSON(input []byte) error {
+	var dec merkleProofJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	m.Root = dec.Root
+	m.Proof = dec.Proof.Siblings
+	return nil
+}
+
+// Hash returns the hash of the root followed by the siblings
+func (m MerkleProof) Hash() common.Hash {
+	data := make([]byte, 0, (merkletree.Height+1)*common.HashLength)
+	data = append(data, m.Root.Bytes()...)
+	for _, sibling := range m.Proof {
+		data = append(data, sibling.Bytes()...)
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+// L1InfoTreeLeafInner is the hashed part of an L1 info tree leaf
+type L1InfoTreeLeafInner struct {
+	GlobalExitRoot common.Hash `json:"global_exit_root"`
+	BlockHash      common.Hash `json:"block_hash"`
+	Timestamp      uint64      `json:"timestamp"`
+}
+
+// Hash returns the hash of the leaf, as stored in the L1 info tree
+func (l L1InfoTreeLeafInner) Hash() common.Hash {
+	return merkletree.L1InfoTreeLeafHash(l.GlobalExitRoot, l.BlockHash, l.Timestamp)
+}
+
+// L1InfoTreeLeaf is the L1 info tree leaf a claim is proven against
+type L1InfoTreeLeaf struct {
+	L1InfoTreeIndex uint32              `json:"l1_info_tree_index"`
+	RollupExitRoot  common.Hash         `json:"rer"`
+	MainnetExitRoot common.Hash         `json:"mer"`
+	Inner           L1InfoTreeLeafInner `json:"inner"`
+}
+
+// Claim is the proof of an imported bridge exit, either a ClaimFromMainnet or
+// a ClaimFromRollup
+type Claim interface {
+	json.Marshaler
+	Hash() common.Hash
+}
+
+// ClaimFromMainnet proves a bridge exit of mainnet
+type ClaimFromMainnet struct {
+	ProofLeafMER     MerkleProof    `json:"proof_leaf_mer"`
+	ProofGERToL1Root MerkleProof    `json:"proof_ger_l1root"`
+	L1Leaf           L1InfoTreeLeaf `json:"l1_leaf"`
+}
+
+// MarshalJSON encodes the claim as the Mainnet variant
+func (c *ClaimFromMainnet) MarshalJSON() ([]byte, error) {
+	type fields ClaimFromMainnet
+	return json.Marshal(map[string]*fields{"Mainnet": (*fields)(c)})
+}
+
+// Hash returns the hash of the proofs and the L1 info tree leaf
+func (c *ClaimFromMainnet) Hash() common.Hash {
+	return crypto.Keccak256Hash(c.ProofLeafMER.Hash().Bytes(), c.ProofGERToL1Root.Hash().Bytes(), c.L1Leaf.Inner.Hash().Bytes())
+}
+
+// ClaimFromRollup proves a bridge exit of a rollup
+type ClaimFromRollup struct {
+	ProofLeafLER     MerkleProof    `json:"proof_leaf_ler"`
+	ProofLERToRER    MerkleProof    `json:"proof_ler_rer"`
+	ProofGERToL1Root MerkleProof    `json:"proof_ger_l1root"`
+	L1Leaf           L1InfoTreeLeaf `json:"l1_leaf"`
+}
+
+// MarshalJSON encodes the claim as the Rollup variant
+func (c *ClaimFromRollup) MarshalJSON() ([]byte, error) {
+	type fields ClaimFromRollup
+	return json.Marshal(map[string]*fields{"Rollup": (*fields)(c)})
+}
+
+// Hash returns the hash of the proofs and the L1 info tree leaf
+func (c *ClaimFromRollup) Hash() common.Hash {
+	return crypto.Keccak256Hash(c.ProofLeafLER.Hash().Bytes(), c.ProofLERToRER.Hash().Bytes(),
+		c.ProofGERToL1Root.Hash().Bytes(), c.L1Leaf.Inner.Hash().Bytes())
+}
+
+// NewBridgeExit returns a bridge exit, the metadata is hashed when not empty
+// as the agglayer only receives its hash
+func NewBridgeExit(leafType agglayer.LeafType, originNetwork agglayer.NetworkID, originTokenAddress common.Address,
+	destNetwork agglayer.NetworkID, destAddress common.Address, amount *big.Int, metadata []byte,
+) agglayer.BridgeExit {
+	exit := agglayer.BridgeExit{
+		LeafType:    leafType,
+		TokenInfo:   agglayer.TokenInfo{OriginNetwork: originNetwork, OriginTokenAddress: originTokenAddress},
+		DestNetwork: destNetwork,
+		DestAddress: destAddress,
+		Amount:      agglayer.NewU256(amount),
+	}
+	if len(metadata) > 0 {
+		metadataHash := crypto.Keccak256Hash(metadata)
+		exit.Metadata = &metadataHash
+	}
+	return exit
+}
+
+// Builder builds a certificate step by step. Nothing is checked, so that
+// malformed certificates can be built on purpose.
+type Builder struct {
+	certificate agglayer.Certificate
+	err         error
+}
+
+// NewBuilder starts a certificate of the network at the height, without
+// bridge exits
+func NewBuilder(networkID agglayer.NetworkID, height agglayer.Height) *Builder {
+	return &Builder{certificate: agglayer.Certificate{
+		NetworkID:           networkID,
+		Height:              height,
+		BridgeExits:         []agglayer.BridgeExit{},
+		ImportedBridgeExits: []agglayer.ImportedBridgeExit{},
+	}}
+}
+
+// PrevLocalExitRoot sets the local exit root the certificate starts from
+func (b *Builder) PrevLocalExitRoot(root common.Hash) *Builder {
+	b.certificate.PrevLocalExitRoot = root
+	return b
+}
+
+// NewLocalExitRoot sets the local exit root after the bridge exits
+func (b *Builder) NewLocalExitRoot(root common.Hash) *Builder {
+	b.certificate.NewLocalExitRoot = root
+	return b
+}
+
+// BridgeExit appends a bridge exit
+func (b *Builder) BridgeExit(exit agglayer.BridgeExit) *Builder {
+	b.certificate.BridgeExits = append(b.certificate.BridgeExits, exit)
+	return b
+}
+
+// ImportedBridgeExit appends the claim of a bridge exit of another network
+func (b *Builder) ImportedBridgeExit(exit agglayer.BridgeExit, globalIndex agglayer.GlobalIndex, claim Claim) *Builder {
+	claimData, err := json.Marshal(claim)
+	if err != nil && b.err == nil {
+		b.err = fmt.Errorf("failed to encode claim of global index %+v: %w", globalIndex, err)
+	}
+	b.certificate.ImportedBridgeExits = append(b.certificate.ImportedBridgeExits, agglayer.ImportedBridgeExit{
+		BridgeExit:  exit,
+		ClaimData:   claimData,
+		GlobalIndex: globalIndex,
+	})
+	return b
+}
+
+// L1InfoTreeLeafCount sets the number of L1 info tree leaves the claims are
+// proven against
+func (b *Builder) L1InfoTreeLeafCount(count uint32) *Builder {
+	b.certificate.L1InfoTreeLeafCount = &count
+	return b
+}
+
+// Metadata sets the metadata of the certificate
+func (b *Builder) Metadata(metadata common.Hash) *Builder {
+	b.certificate.Metadata = metadata
+	return b
+}
+
+// ComputeNewLocalExitRoot appends the bridge exits added so far to the local
+// exit tree and sets the previous and new local exit roots from it. The tree
+// is updated, pass a tree with the leaves of the previous certificates.
+func (b *Builder) ComputeNewLocalExitRoot(tree *merkletree.Tree) *Builder {
+	b.certificate.PrevLocalExitRoot = tree.Root()
+	for _, exit := range b.certificate.BridgeExits {
+		hash, err := BridgeExitHash(exit)
+		if err != nil {
+			if b.err == nil {
+				b.err = err
+			}
+			return b
+		}
+		tree.Add(hash)
+	}
+	b.certificate.NewLocalExitRoot = tree.Root()
+	return b
+}
+
+// Build returns the certificate, without signature
+func (b *Builder) Build() (*agglayer.Certificate, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	certificate := b.certificate
+	certificate.BridgeExits = append([]agglayer.BridgeExit{}, b.certificate.BridgeExits...)
+	certificate.ImportedBridgeExits = append([]agglayer.ImportedBridgeExit{}, b.certificate.ImportedBridgeExits...)
+	return &certificate, nil
+}
@@ -0,0 +1,132 @@
+package certificate
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dummyProof(root common.Hash) MerkleProof {
+	proof := MerkleProof{Root: root}
+	for i := range proof.Proof {
+		proof.Proof[i] = common.BigToHash(big.NewInt(int64(i)))
+	}
+	return proof
+}
+
+func TestHashes(t *testing.T) {
+	// reference values of the aggsender
+	exit := NewBridgeExit(agglayer.LeafTypeTransfer, 0, common.Address{}, 1,
+		common.HexToAddress("0xc949254d682d8c9ad5682521675b8f43b102aec4"), new(big.Int).SetUint64(10000000000000000000), nil)
+	hash, err := BridgeExitHash(exit)
+	require.NoError(t, err)
+	assert.Equal(t, "0x22ed288677b4c2afd83a6d7d55f7df7f4eaaf60f7310210c030fd27adacbc5e0", hash.Hex())
+
+	exit = NewBridgeExit(agglayer.LeafTypeTransfer, 1, common.HexToAddress("0x789"), 2, //nolint:mnd
+		common.HexToAddress("0xabcdef"), big.NewInt(2201), []byte{0x05, 0x08}) //nolint:mnd
+	claim := &ClaimFromRollup{
+		ProofLeafLER:     dummyProof(common.HexToHash("0x333")),
+		ProofLERToRER:    dummyProof(common.HexToHash("0x444")),
+		ProofGERToL1Root: dummyProof(common.HexToHash("0x555")),
+		L1Leaf: L1InfoTreeLeaf{
+			L1InfoTreeIndex: 2, //nolint:mnd
+			RollupExitRoot:  common.HexToHash("0x532"),
+			MainnetExitRoot: common.HexToHash("0x654321"),
+			Inner: L1InfoTreeLeafInner{
+				GlobalExitRoot: common.HexToHash("0x777"),
+				BlockHash:      common.HexToHash("0x888"),
+				Timestamp:      12345678, //nolint:mnd
+			},
+		},
+	}
+	exitHash, err := BridgeExitHash(exit)
+	require.NoError(t, err)
+	globalIndexHash := GlobalIndexHash(agglayer.GlobalIndex{RollupIndex: 1, LeafIndex: 2})
+	assert.Equal(t, "0x6d9dc59396058ef7845fd872a87e77f1a58d010a760957f8814bd3d2ca5914a1",
+		crypto.Keccak256Hash(exitHash.Bytes(), claim.Hash().Bytes(), globalIndexHash.Bytes()).Hex())
+
+	_, err = BridgeExitHash(agglayer.BridgeExit{LeafType: "Unknown"})
+	require.ErrorIs(t, err, ErrUnknownLeafType)
+}
+
+func TestCommitment(t *testing.T) {
+	certificate := &agglayer.Certificate{
+		NewLocalExitRoot: common.HexToHash("0x1"),
+		ImportedBridgeExits: []agglayer.ImportedBridgeExit{
+			{GlobalIndex: agglayer.GlobalIndex{MainnetFlag: true, LeafIndex: 1}},
+			{GlobalIndex: agglayer.GlobalIndex{RollupIndex: 1, LeafIndex: 2}},
+		},
+	}
+
+	// the global indexes are 2^64+1 and 2^32+2, hashed as 32 bytes little endian
+	first, second := make([]byte, 32), make([]byte, 32) //nolint:mnd
+	first[0], first[8] = 1, 1
+	second[0], second[4] = 2, 1
+	expected := crypto.Keccak256Hash(common.HexToHash("0x1").Bytes(),
+		crypto.Keccak256(crypto.Keccak256(first), crypto.Keccak256(second)))
+	assert.Equal(t, expected, Commitment(certificate))
+
+	certificate.ImportedBridgeExits = nil
+	assert.Equal(t, crypto.Keccak256Hash(common.HexToHash("0x1").Bytes(), crypto.Keccak256()), Commitment(certificate))
+}
+
+func TestBuilder(t *testing.T) {
+	tree := merkletree.NewTree(common.HexToHash("0xaa"))
+	prev := tree.Root()
+
+	exit := NewBridgeExit(agglayer.LeafTypeMessage, 1, common.HexToAddress("0x70c3"), 0, common.HexToAddress("0xde57"), big.NewInt(5), []byte("data")) //nolint:mnd
+	imported := NewBridgeExit(agglayer.LeafTypeTransfer, 0, common.Address{}, 1, common.HexToAddress("0xde57"), big.NewInt(7), nil)                    //nolint:mnd
+	claim := &ClaimFromMainnet{
+		ProofLeafMER:     dummyProof(common.HexToHash("0x333")),
+		ProofGERToL1Root: dummyProof(common.HexToHash("0x444")),
+		L1Leaf:           L1InfoTreeLeaf{L1InfoTreeIndex: 4}, //nolint:mnd
+	}
+
+	globalIndex := agglayer.GlobalIndex{MainnetFlag: true, LeafIndex: 9} //nolint:mnd
+	height, leafCount := agglayer.Height(3), uint32(5)                   //nolint:mnd
+	builder := NewBuilder(1, height).
+		BridgeExit(exit).
+		ImportedBridgeExit(imported, globalIndex, claim).
+		L1InfoTreeLeafCount(leafCount).
+		Metadata(common.HexToHash("0xdef")).
+		ComputeNewLocalExitRoot(tree)
+	certificate, err := builder.Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, agglayer.NetworkID(1), certificate.NetworkID)
+	assert.Equal(t, agglayer.Height(3), certificate.Height)
+	assert.Equal(t, prev, certificate.PrevLocalExitRoot)
+	exitHash, err := BridgeExitHash(exit)
+	require.NoError(t, err)
+	assert.Equal(t, merkletree.NewTree(common.HexToHash("0xaa"), exitHash).Root(), certificate.NewLocalExitRoot)
+	assert.Equal(t, uint32(2), tree.Count())
+	require.NotNil(t, certificate.L1InfoTreeLeafCount)
+	assert.Equal(t, uint32(5), *certificate.L1InfoTreeLeafCount)
+	assert.Equal(t, crypto.Keccak256Hash([]byte("data")), *certificate.BridgeExits[0].Metadata)
+	assert.Nil(t, certificate.ImportedBridgeExits[0].BridgeExit.Metadata)
+
+	var claimData map[string]ClaimFromMainnet
+	require.NoError(t, json.Unmarshal(certificate.ImportedBridgeExits[0].ClaimData, &claimData))
+	assert.Equal(t, *claim, claimData["Mainnet"])
+
+	encoded, err := json.Marshal(certificate)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"proof_leaf_mer":{"root":"0x0000000000000000000000000000000000000000000000000000000000000333","proof":{"siblings":[`)
+
+	// the builder output is not shared with the next builds
+	certificate.BridgeExits[0].DestNetwork = 7
+	again, err := builder.BridgeExit(exit).Build()
+	require.NoError(t, err)
+	assert.Len(t, again.BridgeExits, 2) //nolint:mnd
+	assert.Equal(t, agglayer.NetworkID(0), again.BridgeExits[0].DestNetwork)
+
+	_, err = NewBuilder(1, 0).BridgeExit(agglayer.BridgeExit{LeafType: "Unknown"}).ComputeNewLocalExitRoot(merkletree.NewTree()).Build()
+	require.ErrorIs(t, err, ErrUnknownLeafType)
+}
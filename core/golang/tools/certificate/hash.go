@@ -0,0 +1,74 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/agglayer/e2e/core/golang/tools/globalindex"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EmptyMetadataHash is the metadata hash of the bridge exits without metadata
+var EmptyMetadataHash = crypto.Keccak256Hash()
+
+// ErrUnknownLeafType is returned when hashing a bridge exit with a leaf type
+// that is neither Transfer nor Message
+var ErrUnknownLeafType = errors.New("unknown leaf type")
+
+// LeafTypeValue returns the value of the leaf type in the exit tree leaves
+func LeafTypeValue(leafType agglayer.LeafType) (uint8, error) {
+	switch leafType {
+	case agglayer.LeafTypeTransfer:
+		return 0, nil
+	case agglayer.LeafTypeMessage:
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("%w %q", ErrUnknownLeafType, leafType)
+	}
+}
+
+// BridgeExitHash returns the hash of the bridge exit, which is its leaf in
+// the local exit tree
+func BridgeExitHash(exit agglayer.BridgeExit) (common.Hash, error) {
+	leafType, err := LeafTypeValue(exit.LeafType)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	metadataHash := EmptyMetadataHash
+	if exit.Metadata != nil {
+		metadataHash = *exit.Metadata
+	}
+	return merkletree.ExitLeafHash(leafType, uint32(exit.TokenInfo.OriginNetwork), exit.TokenInfo.OriginTokenAddress,
+		uint32(exit.DestNetwork), exit.DestAddress, exit.Amount.Int, metadataHash), nil
+}
+
+// GlobalIndexHash returns the hash of the global index as committed in the
+// certificate signature, the keccak of its 32 bytes little endian value
+func GlobalIndexHash(index agglayer.GlobalIndex) common.Hash {
+	value := globalindex.GlobalIndex{
+		Mainnet:     index.MainnetFlag,
+		RollupIndex: index.RollupIndex,
+		LocalIndex:  index.LeafIndex,
+	}.Big()
+
+	var littleEndian [common.HashLength]byte
+	bigEndian := value.Bytes()
+	for i := range bigEndian {
+		littleEndian[i] = bigEndian[len(bigEndian)-1-i]
+	}
+	return crypto.Keccak256Hash(littleEndian[:])
+}
+
+// Commitment returns the hash the network signs to send the certificate, it
+// commits to the new local exit root and to the global indexes of the
+// imported bridge exits
+func Commitment(certificate *agglayer.Certificate) common.Hash {
+	globalIndexHashes := make([][]byte, len(certificate.ImportedBridgeExits))
+	for i, imported := range certificate.ImportedBridgeExits {
+		globalIndexHashes[i] = GlobalIndexHash(imported.GlobalIndex).Bytes()
+	}
+	return crypto.Keccak256Hash(certificate.NewLocalExitRoot.Bytes(), crypto.Keccak256(globalIndexHashes...))
+}
@@ -0,0 +1,175 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrNotInCommittee is returned when multisig signing with a key whose
+// address is not a committee signer
+var ErrNotInCommittee = errors.New("signer not in committee")
+
+const signatureLength = 65
+
+// Signature is an ECDSA signature, encoded as expected by the agglayer
+type Signature struct {
+	R          common.Hash `json:"r"`
+	S          common.Hash `json:"s"`
+	OddYParity bool        `json:"odd_y_parity"`
+}
+
+// SignHash signs the hash with the key, without any prefix
+func SignHash(hash common.Hash, key *ecdsa.PrivateKey) (*Signature, error) {
+	sig, err := crypto.Sign(hash.Bytes(), key)
+	if err != nil {
+		return nil, err
+	}
+	return SignatureFromBytes(sig)
+}
+
+// SignatureFromBytes decodes a 65 bytes r, s, v signature, v being 0, 1, 27
+// or 28
+func SignatureFromBytes(sig []byte) (*Signature, error) {
+	if len(sig) != signatureLength {
+		return nil, fmt.Errorf("invalid signature length %v", len(sig))
+	}
+	v := sig[64]
+	if v >= 27 { //nolint:mnd
+		v -= 27
+	}
+	if v > 1 {
+		return nil, fmt.Errorf("invalid signature recovery id %v", sig[64])
+	}
+	return &Signature{
+		R:          common.BytesToHash(sig[:32]),
+		S:          common.BytesToHash(sig[32:64]),
+		OddYParity: v == 1,
+	}, nil
+}
+
+// Bytes returns the 65 bytes r, s, v signature, v being 0 or 1
+func (s *Signature) Bytes() []byte {
+	sig := make([]byte, 0, signatureLength)
+	sig = append(sig, s.R.Bytes()...)
+	sig = append(sig, s.S.Bytes()...)
+	if s.OddYParity {
+		return append(sig, 1)
+	}
+	return append(sig, 0)
+}
+
+// Recover returns the address that signed the hash
+func (s *Signature) Recover(hash common.Hash) (common.Address, error) {
+	pub, err := crypto.SigToPub(hash.Bytes(), s.Bytes())
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Sign signs the commitment of the certificate with the key and sets the
+// signature of the certificate
+func Sign(certificate *agglayer.Certificate, key *ecdsa.PrivateKey) (*Signature, error) {
+	sig, err := SignHash(Commitment(certificate), key)
+	if err != nil {
+		return nil, err
+	}
+	return sig, SetSignature(certificate, sig)
+}
+
+// SetSignature sets the signature of the certificate, e.g. a signature of
+// another payload to send an invalid certificate
+func SetSignature(certificate *agglayer.Certificate, sig *Signature) error {
+	encoded, err := json.Marshal(sig)
+	if err != nil {
+		return err
+	}
+	certificate.Signature = encoded
+	return nil
+}
+
+// MultisigSignature is the signature of a committee member, identified by
+// its index in the committee
+type MultisigSignature struct {
+	Index     uint32        `json:"index"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Multisig is the aggchain data of the certificates of an ecdsa-multisig
+// network
+type Multisig struct {
+	Signatures []MultisigSignature `json:"signatures"`
+}
+
+// SignMultisig signs the hash with each key, the keys addresses must be part
+// of the committee. The signatures are sorted by committee index.
+func SignMultisig(hash common.Hash, committee []common.Address, keys ...*ecdsa.PrivateKey) (*Multisig, error) {
+	multisig := &Multisig{Signatures: make([]MultisigSignature, 0, len(keys))}
+	for _, key := range keys {
+		signer := crypto.PubkeyToAddress(key.PublicKey)
+		index := -1
+		for i, member := range committee {
+			if member == signer {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("%w: %v", ErrNotInCommittee, signer.String())
+		}
+
+		sig, err := SignHash(hash, key)
+		if err != nil {
+			return nil, err
+		}
+		multisig.Signatures = append(multisig.Signatures, MultisigSignature{Index: uint32(index), Signature: sig.Bytes()})
+	}
+	sort.SliceStable(multisig.Signatures, func(i, j int) bool {
+		return multisig.Signatures[i].Index < multisig.Signatures[j].Index
+	})
+	return multisig, nil
+}
+
+// Signers returns the addresses recovered from the signatures of the hash
+func (m *Multisig) Signers(hash common.Hash) ([]common.Address, error) {
+	signers := make([]common.Address, len(m.Signatures))
+	for i, entry := range m.Signatures {
+		sig, err := SignatureFromBytes(entry.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("signature of committee index %v: %w", entry.Index, err)
+		}
+		if signers[i], err = sig.Recover(hash); err != nil {
+			return nil, fmt.Errorf("signature of committee index %v: %w", entry.Index, err)
+		}
+	}
+	return signers, nil
+}
+
+// SignCommittee signs the commitment of the certificate with the committee
+// keys and sets the aggchain data of the certificate
+func SignCommittee(certificate *agglayer.Certificate, committee []common.Address, keys ...*ecdsa.PrivateKey) (*Multisig, error) {
+	multisig, err := SignMultisig(Commitment(certificate), committee, keys...)
+	if err != nil {
+		return nil, err
+	}
+	return multisig, SetMultisig(certificate, multisig)
+}
+
+// SetMultisig sets the aggchain data of the certificate to the committee
+// signatures
+func SetMultisig(certificate *agglayer.Certificate, multisig *Multisig) error {
+	encoded, err := json.Marshal(map[string]*Multisig{"multisig": multisig})
+	if err != nil {
+		return err
+	}
+	certificate.AggchainData = encoded
+	return nil
+}
@@ -0,0 +1,75 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	certificate, err := NewBuilder(1, 0).NewLocalExitRoot(common.HexToHash("0x1")).Build()
+	require.NoError(t, err)
+	sig, err := Sign(certificate, key)
+	require.NoError(t, err)
+
+	signer, err := sig.Recover(Commitment(certificate))
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer)
+
+	var decoded Signature
+	require.NoError(t, json.Unmarshal(certificate.Signature, &decoded))
+	assert.Equal(t, *sig, decoded)
+	assert.Contains(t, string(certificate.Signature), `"odd_y_parity"`)
+
+	raw := sig.Bytes()
+	raw[64] += 27
+	fromBytes, err := SignatureFromBytes(raw)
+	require.NoError(t, err)
+	assert.Equal(t, sig, fromBytes)
+
+	_, err = SignatureFromBytes(raw[:64])
+	require.Error(t, err)
+	raw[64] = 5
+	_, err = SignatureFromBytes(raw)
+	require.Error(t, err)
+}
+
+func TestSignCommittee(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3) //nolint:mnd
+	committee := make([]common.Address, len(keys))
+	for i := range keys {
+		var err error
+		keys[i], err = crypto.GenerateKey()
+		require.NoError(t, err)
+		committee[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+
+	certificate := &agglayer.Certificate{NetworkID: 1, NewLocalExitRoot: common.HexToHash("0x1")}
+	multisig, err := SignCommittee(certificate, committee, keys[2], keys[0])
+	require.NoError(t, err)
+	require.Len(t, multisig.Signatures, 2) //nolint:mnd
+	assert.Equal(t, uint32(0), multisig.Signatures[0].Index)
+	assert.Equal(t, uint32(2), multisig.Signatures[1].Index)
+
+	signers, err := multisig.Signers(Commitment(certificate))
+	require.NoError(t, err)
+	assert.Equal(t, []common.Address{committee[0], committee[2]}, signers)
+
+	var aggchainData map[string]Multisig
+	require.NoError(t, json.Unmarshal(certificate.AggchainData, &aggchainData))
+	assert.Equal(t, *multisig, aggchainData["multisig"])
+
+	outsider, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = SignMultisig(Commitment(certificate), committee, keys[0], outsider)
+	require.ErrorIs(t, err, ErrNotInCommittee)
+}
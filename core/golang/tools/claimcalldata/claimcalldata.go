@@ -0,0 +1,346 @@
+package claimcalldata
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/agglayer/e2e/core/golang/tools/globalindex"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/agglayer/e2e/core/golang/tools/zkevmbridgeservice"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Version is a version of the bridge claim methods signature
+type Version int
+
+const (
+	// Etrog is the signature since etrog, with the proofs of the local and
+	// the rollup exit roots and a global index
+	Etrog Version = iota
+	// PreEtrog is the signature before etrog, with a single proof and the
+	// deposit index
+	PreEtrog
+)
+
+// String returns the name of the version
+func (v Version) String() string {
+	switch v {
+	case Etrog:
+		return "etrog"
+	case PreEtrog:
+		return "pre-etrog"
+	default:
+		return fmt.Sprintf("Version(%d)", int(v))
+	}
+}
+
+// ClaimAssetMethod and ClaimMessageMethod are the names of the claim methods
+const (
+	ClaimAssetMethod   = "claimAsset"
+	ClaimMessageMethod = "claimMessage"
+)
+
+const selectorLength = 4
+
+const etrogABI = `[
+	{"type":"function","name":"claimAsset","stateMutability":"nonpayable","outputs":[],"inputs":[
+		{"name":"smtProofLocalExitRoot","type":"bytes32[32]"},
+		{"name":"smtProofRollupExitRoot","type":"bytes32[32]"},
+		{"name":"globalIndex","type":"uint256"},
+		{"name":"mainnetExitRoot","type":"bytes32"},
+		{"name":"rollupExitRoot","type":"bytes32"},
+		{"name":"originNetwork","type":"uint32"},
+		{"name":"originTokenAddress","type":"address"},
+		{"name":"destinationNetwork","type":"uint32"},
+		{"name":"destinationAddress","type":"address"},
+		{"name":"amount","type":"uint256"},
+		{"name":"metadata","type":"bytes"}]},
+	{"type":"function","name":"claimMessage","stateMutability":"nonpayable","outputs":[],"inputs":[
+		{"name":"smtProofLocalExitRoot","type":"bytes32[32]"},
+		{"name":"smtProofRollupExitRoot","type":"bytes32[32]"},
+		{"name":"globalIndex","type":"uint256"},
+		{"name":"mainnetExitRoot","type":"bytes32"},
+		{"name":"rollupExitRoot","type":"bytes32"},
+		{"name":"originNetwork","type":"uint32"},
+		{"name":"originAddress","type":"address"},
+		{"name":"destinationNetwork","type":"uint32"},
+		{"name":"destinationAddress","type":"address"},
+		{"name":"amount","type":"uint256"},
+		{"name":"metadata","type":"bytes"}]}
+]`
+
+const preEtrogABI = `[
+	{"type":"function","name":"claimAsset","stateMutability":"nonpayable","outputs":[],"inputs":[
+		{"name":"smtProof","type":"bytes32[32]"},
+		{"name":"index","type":"uint32"},
+		{"name":"mainnetExitRoot","type":"bytes32"},
+		{"name":"rollupExitRoot","type":"bytes32"},
+		{"name":"originNetwork","type":"uint32"},
+		{"name":"originTokenAddress","type":"address"},
+		{"name":"destinationNetwork","type":"uint32"},
+		{"name":"destinationAddress","type":"address"},
+		{"name":"amount","type":"uint256"},
+		{"name":"metadata","type":"bytes"}]},
+	{"type":"function","name":"claimMessage","stateMutability":"nonpayable","outputs":[],"inputs":[
+		{"name":"smtProof","type":"bytes32[32]"},
+		{"name":"index","type":"uint32"},
+		{"name":"mainnetExitRoot","type":"bytes32"},
+		{"name":"rollupExitRoot","type":"bytes32"},
+		{"name":"originNetwork","type":"uint32"},
+		{"name":"originAddress","type":"address"},
+		{"name":"destinationNetwork","type":"uint32"},
+		{"name":"destinationAddress","type":"address"},
+		{"name":"amount","type":"uint256"},
+		{"name":"metadata","type":"bytes"}]}
+]`
+
+func mustParseABI(definition string) *abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return &parsed
+}
+
+var (
+	// abis are the claim methods of each version
+	abis = map[Version]*abi.ABI{
+		Etrog:    mustParseABI(etrogABI),
+		PreEtrog: mustParseABI(preEtrogABI),
+	}
+
+	// ErrUnknownMethod is returned when decoding calldata of another method
+	ErrUnknownMethod = errors.New("not a claimAsset or claimMessage call")
+	// ErrIndexOutOfRange is returned when encoding a pre etrog claim with a
+	// global index that isn't a valid one of mainnet or the first rollup
+	ErrIndexOutOfRange = errors.New("deposit index out of range")
+)
+
+// Params are the params of a claimAsset or claimMessage call
+type Params struct {
+	// LeafType selects claimAsset or claimMessage
+	LeafType           bridgeservice.LeafType
+	ProofLocalExitRoot merkletree.Proof
+	// ProofRollupExitRoot is not part of the pre etrog calls
+	ProofRollupExitRoot merkletree.Proof
+	// GlobalIndex is encoded as its local deposit index in the pre etrog
+	// calls, which are decoded with that index as global index
+	GlobalIndex        *big.Int
+	MainnetExitRoot    common.Hash
+	RollupExitRoot     common.Hash
+	OriginNetwork      uint32
+	OriginAddress      common.Address
+	DestinationNetwork uint32
+	DestinationAddress common.Address
+	Amount             *big.Int
+	Metadata           []byte
+}
+
+// FromBridge returns the params to claim the bridge sent from the network,
+// as indexed by the aggkit bridge service, with its claim proof
+func FromBridge(networkID uint32, bridge *bridgeservice.Bridge, proof *bridgeservice.ClaimProof) *Params {
+	params := &Params{
+		LeafType:            bridge.LeafType,
+		ProofLocalExitRoot:  merkletree.Proof(proof.ProofLocalExitRoot),
+		ProofRollupExitRoot: merkletree.Proof(proof.ProofRollupExitRoot),
+		GlobalIndex:         globalindex.New(networkID, bridge.DepositCount).Big(),
+		MainnetExitRoot:     proof.L1InfoTreeLeaf.MainnetExitRoot,
+		RollupExitRoot:      proof.L1InfoTreeLeaf.RollupExitRoot,
+		OriginNetwork:       bridge.OriginNetwork,
+		OriginAddress:       bridge.OriginAddress,
+		DestinationNetwork:  bridge.DestinationNetwork,
+		DestinationAddress:  bridge.DestinationAddress,
+		Amount:              new(big.Int),
+		Metadata:            bridge.Metadata,
+	}
+	if bridge.Amount != nil && bridge.Amount.Int != nil {
+		params.Amount.Set(bridge.Amount.Int)
+	}
+	return params
+}
+
+// FromDeposit returns the params to claim the deposit, as indexed by the
+// legacy zkevm bridge service, with its merkle proof
+func FromDeposit(deposit *zkevmbridgeservice.Deposit, proof *zkevmbridgeservice.Proof) *Params {
+	input := zkevmbridgeservice.NewClaimInput(deposit, proof)
+	params := &Params{
+		LeafType:           bridgeservice.LeafType(deposit.LeafType),
+		GlobalIndex:        input.GlobalIndex,
+		MainnetExitRoot:    input.MainnetExitRoot,
+		RollupExitRoot:     input.RollupExitRoot,
+		OriginNetwork:      input.OriginNetwork,
+		OriginAddress:      input.OriginAddress,
+		DestinationNetwork: input.DestinationNetwork,
+		DestinationAddress: input.DestinationAddress,
+		Amount:             input.Amount,
+		Metadata:           input.Metadata,
+	}
+	for i := range input.SmtProofLocalExitRoot {
+		params.ProofLocalExitRoot[i] = input.SmtProofLocalExitRoot[i]
+		params.ProofRollupExitRoot[i] = input.SmtProofRollupExitRoot[i]
+	}
+	return params
+}
+
+// Method returns the name of the claim method for the leaf type
+func (p *Params) Method() string {
+	if p.LeafType == bridgeservice.LeafTypeAsset {
+		return ClaimAssetMethod
+	}
+	return ClaimMessageMethod
+}
+
+// Args returns the params in the order expected by the claim method of the
+// version, e.g. to be sent with bind.BoundContract.Transact
+func (p *Params) Args(version Version) ([]any, error) {
+	localProof, rollupProof := proofBytes(p.ProofLocalExitRoot), proofBytes(p.ProofRollupExitRoot)
+	globalIndex, amount := bigOrZero(p.GlobalIndex), bigOrZero(p.Amount)
+	metadata := p.Metadata
+	if metadata == nil {
+		metadata = []byte{}
+	}
+	tail := []any{
+		[32]byte(p.MainnetExitRoot), [32]byte(p.RollupExitRoot),
+		p.OriginNetwork, p.OriginAddress, p.DestinationNetwork, p.DestinationAddress, amount, metadata,
+	}
+
+	switch version {
+	case Etrog:
+		return append([]any{localProof, rollupProof, globalIndex}, tail...), nil
+	case PreEtrog:
+		// before etrog the only rollup was the first one, and the deposit
+		// index was enough to claim from it or from mainnet
+		index, err := globalindex.Decode(globalIndex)
+		if err != nil || index.RollupIndex != 0 {
+			return nil, fmt.Errorf("%w: %v", ErrIndexOutOfRange, globalIndex)
+		}
+		return append([]any{localProof, index.LocalIndex}, tail...), nil
+	default:
+		return nil, fmt.Errorf("unknown version %v", version)
+	}
+}
+
+// Encode returns the calldata of the claim method of the version
+func (p *Params) Encode(version Version) ([]byte, error) {
+	args, err := p.Args(version)
+	if err != nil {
+		return nil, err
+	}
+	return abis[version].Pack(p.Method(), args...)
+}
+
+// Decode decodes the calldata of a claimAsset or claimMessage call of any
+// version
+func Decode(calldata []byte) (*Params, Version, error) {
+	if len(calldata) < selectorLength {
+		return nil, 0, ErrUnknownMethod
+	}
+	for _, version := range []Version{Etrog, PreEtrog} {
+		method, err := abis[version].MethodById(calldata[:selectorLength])
+		if err != nil {
+			continue
+		}
+		values, err := method.Inputs.Unpack(calldata[selectorLength:])
+		if err != nil {
+			return nil, version, fmt.Errorf("failed to decode %v %v call: %w", version, method.Name, err)
+		}
+		params, err := fromValues(version, values)
+		if err != nil {
+			return nil, version, fmt.Errorf("failed to decode %v %v call: %w", version, method.Name, err)
+		}
+		if method.Name == ClaimMessageMethod {
+			params.LeafType = bridgeservice.LeafTypeMessage
+		}
+		return params, version, nil
+	}
+	return nil, 0, ErrUnknownMethod
+}
+
+// fromValues builds the params from the unpacked arguments of the version,
+// the global index of a pre etrog call is rebuilt from its deposit index and
+// destination network
+func fromValues(version Version, values []any) (*Params, error) {
+	params := &Params{LeafType: bridgeservice.LeafTypeAsset}
+	var ok bool
+	var depositIndex *uint32
+	var localProof, rollupProof [32][32]byte
+	if localProof, ok = values[0].([32][32]byte); !ok {
+		return nil, errors.New("unexpected local exit root proof type")
+	}
+	params.ProofLocalExitRoot = merkletree.Proof(toHashes(localProof))
+
+	if version == Etrog {
+		if rollupProof, ok = values[1].([32][32]byte); !ok {
+			return nil, errors.New("unexpected rollup exit root proof type")
+		}
+		params.ProofRollupExitRoot = merkletree.Proof(toHashes(rollupProof))
+		if params.GlobalIndex, ok = values[2].(*big.Int); !ok {
+			return nil, errors.New("unexpected global index type")
+		}
+		values = values[3:]
+	} else {
+		index, ok := values[1].(uint32)
+		if !ok {
+			return nil, errors.New("unexpected index type")
+		}
+		depositIndex = &index
+		values = values[2:]
+	}
+
+	mainnetExitRoot, ok1 := values[0].([32]byte)
+	rollupExitRoot, ok2 := values[1].([32]byte)
+	originNetwork, ok3 := values[2].(uint32)
+	originAddress, ok4 := values[3].(common.Address)
+	destinationNetwork, ok5 := values[4].(uint32)
+	destinationAddress, ok6 := values[5].(common.Address)
+	amount, ok7 := values[6].(*big.Int)
+	metadata, ok8 := values[7].([]byte)
+	if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 || !ok6 || !ok7 || !ok8 {
+		return nil, errors.New("unexpected argument types")
+	}
+	params.MainnetExitRoot = mainnetExitRoot
+	params.RollupExitRoot = rollupExitRoot
+	params.OriginNetwork = originNetwork
+	params.OriginAddress = originAddress
+	params.DestinationNetwork = destinationNetwork
+	params.DestinationAddress = destinationAddress
+	params.Amount = amount
+	params.Metadata = metadata
+	if depositIndex != nil {
+		// as the pre etrog bridge verifies the leaf, the claims out of
+		// mainnet are from the first rollup and the others from mainnet
+		networkID := uint32(1)
+		if destinationNetwork != 0 {
+			networkID = 0
+		}
+		params.GlobalIndex = globalindex.New(networkID, *depositIndex).Big()
+	}
+	return params, nil
+}
+
+func proofBytes(proof merkletree.Proof) [32][32]byte {
+	var b [32][32]byte
+	for i := range proof {
+		b[i] = proof[i]
+	}
+	return b
+}
+
+func toHashes(proof [32][32]byte) [32]common.Hash {
+	var hashes [32]common.Hash
+	for i := range proof {
+		hashes[i] = proof[i]
+	}
+	return hashes
+}
+
+func bigOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
@@ -0,0 +1,162 @@
+package claimcalldata
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/agglayer/e2e/core/golang/tools/globalindex"
+	"github.com/agglayer/e2e/core/golang/tools/merkletree"
+	"github.com/agglayer/e2e/core/golang/tools/zkevmbridgeservice"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testParams(leafType bridgeservice.LeafType) *Params {
+	params := &Params{
+		LeafType:           leafType,
+		GlobalIndex:        globalindex.New(2, 9).Big(), //nolint:mnd
+		MainnetExitRoot:    common.HexToHash("0x4e7"),
+		RollupExitRoot:     common.HexToHash("0x4011"),
+		OriginNetwork:      2, //nolint:mnd
+		OriginAddress:      common.HexToAddress("0x70c3"),
+		DestinationNetwork: 0,
+		DestinationAddress: common.HexToAddress("0xde57"),
+		Amount:             big.NewInt(1000), //nolint:mnd
+		Metadata:           []byte("metadata"),
+	}
+	for i := range params.ProofLocalExitRoot {
+		params.ProofLocalExitRoot[i] = crypto.Keccak256Hash([]byte{byte(i)})
+		params.ProofRollupExitRoot[i] = merkletree.ZeroHashes[i]
+	}
+	return params
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, leafType := range []bridgeservice.LeafType{bridgeservice.LeafTypeAsset, bridgeservice.LeafTypeMessage} {
+		params := testParams(leafType)
+
+		calldata, err := params.Encode(Etrog)
+		require.NoError(t, err)
+		decoded, version, err := Decode(calldata)
+		require.NoError(t, err)
+		assert.Equal(t, Etrog, version)
+		assert.Equal(t, params, decoded)
+
+		index, err := globalindex.FromClaimCalldata(calldata)
+		require.NoError(t, err)
+		assert.Equal(t, globalindex.New(2, 9), index)
+
+		legacy := testParams(leafType)
+		legacy.GlobalIndex = big.NewInt(9) //nolint:mnd
+		calldata, err = legacy.Encode(PreEtrog)
+		require.NoError(t, err)
+		decoded, version, err = Decode(calldata)
+		require.NoError(t, err)
+		assert.Equal(t, PreEtrog, version)
+		legacy.ProofRollupExitRoot = merkletree.Proof{}
+		assert.Equal(t, legacy, decoded)
+	}
+
+	calldata, err := testParams(bridgeservice.LeafTypeAsset).Encode(Etrog)
+	require.NoError(t, err)
+	assert.Equal(t, globalindex.ClaimAssetSelector, calldata[:4])
+	calldata, err = testParams(bridgeservice.LeafTypeMessage).Encode(Etrog)
+	require.NoError(t, err)
+	assert.Equal(t, globalindex.ClaimMessageSelector, calldata[:4])
+}
+
+func TestEncodeErrors(t *testing.T) {
+	_, err := testParams(bridgeservice.LeafTypeAsset).Encode(PreEtrog)
+	require.ErrorIs(t, err, ErrIndexOutOfRange)
+	params := testParams(bridgeservice.LeafTypeAsset)
+	params.GlobalIndex = new(big.Int).Lsh(big.NewInt(1), 65) //nolint:mnd
+	_, err = params.Encode(PreEtrog)
+	require.ErrorIs(t, err, ErrIndexOutOfRange)
+
+	_, err = testParams(bridgeservice.LeafTypeAsset).Encode(Version(7)) //nolint:mnd
+	require.Error(t, err)
+
+	empty, err := (&Params{}).Encode(Etrog)
+	require.NoError(t, err)
+	decoded, _, err := Decode(empty)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), decoded.Amount.Int64())
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, _, err := Decode(nil)
+	require.ErrorIs(t, err, ErrUnknownMethod)
+
+	_, _, err = Decode(crypto.Keccak256([]byte("transfer(address,uint256)"))[:4])
+	require.ErrorIs(t, err, ErrUnknownMethod)
+
+	calldata, err := testParams(bridgeservice.LeafTypeAsset).Encode(Etrog)
+	require.NoError(t, err)
+	_, _, err = Decode(calldata[:100])
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrUnknownMethod)
+}
+
+func TestFromServices(t *testing.T) {
+	bridge := &bridgeservice.Bridge{
+		LeafType:           bridgeservice.LeafTypeMessage,
+		OriginNetwork:      1,
+		OriginAddress:      common.HexToAddress("0x70c3"),
+		DestinationNetwork: 0,
+		DestinationAddress: common.HexToAddress("0xde57"),
+		Amount:             bridgeservice.NewBigIntString(big.NewInt(5)), //nolint:mnd
+		Metadata:           []byte{0x1},
+		DepositCount:       3, //nolint:mnd
+	}
+	proof := &bridgeservice.ClaimProof{
+		ProofLocalExitRoot: bridgeservice.Proof{common.HexToHash("0x1")},
+		L1InfoTreeLeaf:     bridgeservice.L1InfoTreeLeaf{MainnetExitRoot: common.HexToHash("0x2"), RollupExitRoot: common.HexToHash("0x3")},
+	}
+	params := FromBridge(1, bridge, proof)
+	assert.Equal(t, ClaimMessageMethod, params.Method())
+	assert.Equal(t, globalindex.New(1, 3).Big(), params.GlobalIndex)
+	assert.Equal(t, common.HexToHash("0x1"), params.ProofLocalExitRoot[0])
+	assert.Equal(t, common.HexToHash("0x3"), params.RollupExitRoot)
+	assert.Equal(t, int64(5), params.Amount.Int64())
+
+	// the pre etrog calls only carry the deposit index, the global index is
+	// rebuilt from the destination network
+	calldata, err := params.Encode(PreEtrog)
+	require.NoError(t, err)
+	decoded, version, err := Decode(calldata)
+	require.NoError(t, err)
+	assert.Equal(t, PreEtrog, version)
+	assert.Equal(t, globalindex.New(1, 3).String(), decoded.GlobalIndex.String())
+
+	mainnetBridge := *bridge
+	mainnetBridge.DestinationNetwork = 1
+	mainnetParams := FromBridge(0, &mainnetBridge, proof)
+	calldata, err = mainnetParams.Encode(PreEtrog)
+	require.NoError(t, err)
+	decoded, _, err = Decode(calldata)
+	require.NoError(t, err)
+	assert.Equal(t, globalindex.New(0, 3).String(), decoded.GlobalIndex.String())
+	index, err := globalindex.Decode(decoded.GlobalIndex)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), index.NetworkID())
+	assert.Equal(t, uint32(3), index.LocalIndex)
+	assert.Equal(t, uint32(1), decoded.DestinationNetwork)
+
+	deposit := &zkevmbridgeservice.Deposit{
+		LeafType:    zkevmbridgeservice.LeafTypeAsset,
+		DestNet:     1,
+		Amount:      zkevmbridgeservice.BigInt{Int: big.NewInt(7)},       //nolint:mnd
+		GlobalIndex: zkevmbridgeservice.BigInt{Int: big.NewInt(1 << 32)}, //nolint:mnd
+	}
+	depositProof := &zkevmbridgeservice.Proof{MainExitRoot: common.HexToHash("0x4")}
+	depositProof.RollupMerkleProof[1] = common.HexToHash("0x5")
+	params = FromDeposit(deposit, depositProof)
+	assert.Equal(t, ClaimAssetMethod, params.Method())
+	assert.Equal(t, int64(1<<32), params.GlobalIndex.Int64())
+	assert.Equal(t, common.HexToHash("0x4"), params.MainnetExitRoot)
+	assert.Equal(t, common.HexToHash("0x5"), params.ProofRollupExitRoot[1])
+	assert.Equal(t, int64(7), params.Amount.Int64())
+}
@@ -0,0 +1,167 @@
+package committee
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/agglayer/e2e/core/golang/tools/certificate"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrInvalidThreshold is returned when the threshold is zero or greater
+	// than the committee size
+	ErrInvalidThreshold = errors.New("invalid threshold")
+	// ErrUnknownMember is returned when a member index is out of the committee
+	ErrUnknownMember = errors.New("unknown committee member")
+	// ErrInvalidSignature is returned when a signature doesn't recover to the
+	// committee member of its index
+	ErrInvalidSignature = errors.New("invalid committee signature")
+	// ErrDuplicateSigner is returned when a member signed more than once
+	ErrDuplicateSigner = errors.New("duplicate committee signer")
+	// ErrThresholdNotReached is returned when there are fewer signatures than
+	// the threshold
+	ErrThresholdNotReached = errors.New("threshold not reached")
+)
+
+// Member is a committee member
+type Member struct {
+	Key     *ecdsa.PrivateKey
+	Address common.Address
+	// URL is the validator endpoint registered in the aggchain signer infos
+	URL string
+}
+
+// Committee is a set of signers and the number of signatures required
+type Committee struct {
+	Members   []Member
+	Threshold uint64
+}
+
+// New creates a committee of the keys, in order
+func New(threshold uint64, keys ...*ecdsa.PrivateKey) (*Committee, error) {
+	if threshold == 0 || threshold > uint64(len(keys)) {
+		return nil, fmt.Errorf("%w: %v of %v signers", ErrInvalidThreshold, threshold, len(keys))
+	}
+	c := &Committee{Members: make([]Member, len(keys)), Threshold: threshold}
+	for i, key := range keys {
+		c.Members[i] = Member{Key: key, Address: crypto.PubkeyToAddress(key.PublicKey)}
+	}
+	return c, nil
+}
+
+// Generate creates a committee of n new keys
+func Generate(n int, threshold uint64) (*Committee, error) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		var err error
+		if keys[i], err = crypto.GenerateKey(); err != nil {
+			return nil, err
+		}
+	}
+	return New(threshold, keys...)
+}
+
+// Signers returns the addresses of the members, in committee order
+func (c *Committee) Signers() []common.Address {
+	signers := make([]common.Address, len(c.Members))
+	for i, member := range c.Members {
+		signers[i] = member.Address
+	}
+	return signers
+}
+
+// MultisigHash returns the hash of the signers and threshold the aggchain
+// contract stores, keccak256(abi.encodePacked(threshold, signers))
+func (c *Committee) MultisigHash() common.Hash {
+	// abi.encodePacked pads the array elements to 32 bytes
+	data := make([]byte, 0, common.HashLength*(len(c.Members)+1))
+	data = append(data, common.BigToHash(new(big.Int).SetUint64(c.Threshold)).Bytes()...)
+	for _, member := range c.Members {
+		data = append(data, common.BytesToHash(member.Address.Bytes()).Bytes()...)
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+// SignerInfosArg returns the members as the (address,string)[] argument of
+// updateSignersAndThreshold, in cast syntax
+func (c *Committee) SignerInfosArg() string {
+	infos := make([]string, len(c.Members))
+	for i, member := range c.Members {
+		infos[i] = fmt.Sprintf("(%v,%q)", member.Address.String(), member.URL)
+	}
+	return "[" + strings.Join(infos, ",") + "]"
+}
+
+// Keys returns the keys of the members of the indexes, e.g. to sign with a
+// subset of the committee
+func (c *Committee) Keys(indexes ...int) ([]*ecdsa.PrivateKey, error) {
+	keys := make([]*ecdsa.PrivateKey, len(indexes))
+	for i, index := range indexes {
+		if index < 0 || index >= len(c.Members) {
+			return nil, fmt.Errorf("%w: index %v of %v members", ErrUnknownMember, index, len(c.Members))
+		}
+		keys[i] = c.Members[index].Key
+	}
+	return keys, nil
+}
+
+// SignHash signs the hash with the members of the indexes
+func (c *Committee) SignHash(hash common.Hash, indexes ...int) (*certificate.Multisig, error) {
+	keys, err := c.Keys(indexes...)
+	if err != nil {
+		return nil, err
+	}
+	return certificate.SignMultisig(hash, c.Signers(), keys...)
+}
+
+// SignCertificate signs the commitment of the certificate with the members of
+// the indexes and sets the aggchain data of the certificate
+func (c *Committee) SignCertificate(cert *agglayer.Certificate, indexes ...int) (*certificate.Multisig, error) {
+	keys, err := c.Keys(indexes...)
+	if err != nil {
+		return nil, err
+	}
+	return certificate.SignCommittee(cert, c.Signers(), keys...)
+}
+
+// SignGlobalExitRoot signs the global exit root with the members of the
+// indexes, as an oracle committee vote on it
+func (c *Committee) SignGlobalExitRoot(ger common.Hash, indexes ...int) (*certificate.Multisig, error) {
+	return c.SignHash(ger, indexes...)
+}
+
+// Verify checks the signatures of the hash are from distinct members and reach
+// the threshold, as the agglayer does before accepting a certificate
+func (c *Committee) Verify(hash common.Hash, multisig *certificate.Multisig) error {
+	signed := make(map[uint32]bool, len(multisig.Signatures))
+	for _, entry := range multisig.Signatures {
+		if int(entry.Index) >= len(c.Members) {
+			return fmt.Errorf("%w: index %v of %v members", ErrUnknownMember, entry.Index, len(c.Members))
+		}
+		if signed[entry.Index] {
+			return fmt.Errorf("%w: index %v", ErrDuplicateSigner, entry.Index)
+		}
+		sig, err := certificate.SignatureFromBytes(entry.Signature)
+		if err != nil {
+			return fmt.Errorf("%w: index %v: %w", ErrInvalidSignature, entry.Index, err)
+		}
+		signer, err := sig.Recover(hash)
+		if err != nil {
+			return fmt.Errorf("%w: index %v: %w", ErrInvalidSignature, entry.Index, err)
+		}
+		if signer != c.Members[entry.Index].Address {
+			return fmt.Errorf("%w: index %v recovers to %v", ErrInvalidSignature, entry.Index, signer.String())
+		}
+		signed[entry.Index] = true
+	}
+	if uint64(len(signed)) < c.Threshold {
+		return fmt.Errorf("%w: %v of %v signatures", ErrThresholdNotReached, len(signed), c.Threshold)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package committee
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/agglayer/e2e/core/golang/tools/certificate"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultisigHash(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	c, err := New(1, key)
+	require.NoError(t, err)
+
+	// abi.encodePacked(uint256(1), [signer])
+	expected := crypto.Keccak256Hash(common.BigToHash(common.Big1).Bytes(), common.LeftPadBytes(c.Members[0].Address.Bytes(), 32)) //nolint:mnd
+	assert.Equal(t, expected, c.MultisigHash())
+
+	c.Threshold = 2
+	assert.NotEqual(t, expected, c.MultisigHash())
+
+	_, err = New(0, key)
+	require.ErrorIs(t, err, ErrInvalidThreshold)
+	_, err = New(2, key) //nolint:mnd
+	require.ErrorIs(t, err, ErrInvalidThreshold)
+}
+
+func TestSign(t *testing.T) {
+	c, err := Generate(3, 2) //nolint:mnd
+	require.NoError(t, err)
+	c.Members[1].URL = ValidatorURL(2) //nolint:mnd
+	assert.Equal(t, "[("+c.Members[0].Address.String()+`,""),(`+c.Members[1].Address.String()+
+		`,"http://aggkit-001-aggsender-validator-002:5578"),(`+c.Members[2].Address.String()+`,"")]`, c.SignerInfosArg())
+
+	cert := &agglayer.Certificate{NetworkID: 1, NewLocalExitRoot: common.HexToHash("0x1")}
+	multisig, err := c.SignCertificate(cert, 2, 0) //nolint:mnd
+	require.NoError(t, err)
+	require.NoError(t, c.Verify(certificate.Commitment(cert), multisig))
+	require.NotEmpty(t, cert.AggchainData)
+
+	multisig, err = c.SignCertificate(cert, 1)
+	require.NoError(t, err)
+	require.ErrorIs(t, c.Verify(certificate.Commitment(cert), multisig), ErrThresholdNotReached)
+
+	multisig.Signatures = append(multisig.Signatures, multisig.Signatures[0])
+	require.ErrorIs(t, c.Verify(certificate.Commitment(cert), multisig), ErrDuplicateSigner)
+
+	ger := common.HexToHash("0x9e7")
+	multisig, err = c.SignGlobalExitRoot(ger, 0, 1)
+	require.NoError(t, err)
+	require.NoError(t, c.Verify(ger, multisig))
+	require.ErrorIs(t, c.Verify(common.HexToHash("0x1"), multisig), ErrInvalidSignature)
+
+	multisig.Signatures[1].Index = 3
+	require.ErrorIs(t, c.Verify(ger, multisig), ErrUnknownMember)
+	_, err = c.SignHash(ger, 3) //nolint:mnd
+	require.ErrorIs(t, err, ErrUnknownMember)
+}
+
+func TestKeystores(t *testing.T) {
+	c, err := Generate(2, 1) //nolint:mnd
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	paths, err := c.WriteKeystores(dir, 3, ValidatorKeystoreFile, "secret") //nolint:mnd
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "aggsendervalidator-4.keystore"), paths[1])
+
+	key, err := ReadKeystore(paths[1], "secret")
+	require.NoError(t, err)
+	assert.Equal(t, c.Members[1].Address, crypto.PubkeyToAddress(key.PublicKey))
+	_, err = ReadKeystore(paths[1], "wrong")
+	require.Error(t, err)
+	_, err = os.Stat(paths[0])
+	require.NoError(t, err)
+
+	assert.Equal(t, `[Validator]
+EnableRPC = true
+Signer = { Method = "local", Path = "/etc/aggkit/aggsendervalidator-4.keystore", Password = "secret" }
+Mode = "PessimisticProof"
+`, ValidatorConfig("/etc/aggkit/"+ValidatorKeystoreFile(4), "secret")) //nolint:mnd
+	fragment := AggOracleConfig(common.HexToAddress("0x6d1569bE9F7f1AB6C1221937Bc3E6e76e182EF54"), "/etc/aggkit/"+AggOracleKeystoreFile(2), "secret") //nolint:mnd
+	assert.Contains(t, fragment, `AggOracleCommitteeAddr = "0x6d1569bE9F7f1AB6C1221937Bc3E6e76e182EF54"`)
+	assert.Contains(t, fragment, `PrivateKeys = [{Path = "/etc/aggkit/aggoracle-2.keystore", Password = "secret"}]`)
+}
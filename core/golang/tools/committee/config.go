@@ -0,0 +1,97 @@
+package committee
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// ValidatorKeystoreFile is the keystore file name of the aggsender validator
+// number, as in the attach-new-committee-members scenario
+func ValidatorKeystoreFile(number int) string {
+	return fmt.Sprintf("aggsendervalidator-%d.keystore", number)
+}
+
+// AggOracleKeystoreFile is the keystore file name of the aggoracle committee
+// member number
+func AggOracleKeystoreFile(number int) string {
+	return fmt.Sprintf("aggoracle-%d.keystore", number)
+}
+
+// ValidatorURL is the endpoint of the aggsender validator number in the
+// kurtosis network
+func ValidatorURL(number int) string {
+	return fmt.Sprintf("http://aggkit-001-aggsender-validator-%03d:5578", number)
+}
+
+// ValidatorConfig returns the aggkit config fragment of an aggsender
+// validator serving its RPC and signing pessimistic proof certificates with
+// the keystore
+func ValidatorConfig(keystorePath, password string) string {
+	return fmt.Sprintf("[Validator]\nEnableRPC = true\n"+
+		"Signer = { Method = \"local\", Path = %q, Password = %q }\n"+
+		"Mode = \"PessimisticProof\"\n",
+		keystorePath, password)
+}
+
+// AggOracleConfig returns the aggkit config fragment of an aggoracle committee
+// member sending its votes with the keystore
+func AggOracleConfig(committeeAddr common.Address, keystorePath, password string) string {
+	return fmt.Sprintf("[AggOracle.EVMSender]\nAggOracleCommitteeAddr = %q\n\n"+
+		"[AggOracle.EVMSender.EthTxManager]\nPrivateKeys = [{Path = %q, Password = %q}]\n",
+		committeeAddr.String(), keystorePath, password)
+}
+
+// WriteKeystore writes the key encrypted with the password to the path, with
+// light scrypt parameters to keep the tests fast
+func WriteKeystore(path string, key *ecdsa.PrivateKey, password string) error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	encrypted, err := keystore.EncryptKey(&keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	}, password, keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil { //nolint:mnd
+		return err
+	}
+	return os.WriteFile(path, encrypted, 0o600) //nolint:mnd
+}
+
+// ReadKeystore decrypts the key of the keystore at the path
+func ReadKeystore(path, password string) (*ecdsa.PrivateKey, error) {
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(encrypted, password)
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey, nil
+}
+
+// WriteKeystores writes the keystore of each member to the directory, the
+// file name being given by the member number, starting at first. It returns
+// the keystore paths.
+func (c *Committee) WriteKeystores(dir string, first int, name func(number int) string, password string) ([]string, error) {
+	paths := make([]string, len(c.Members))
+	for i, member := range c.Members {
+		paths[i] = filepath.Join(dir, name(first+i))
+		if err := WriteKeystore(paths[i], member.Key, password); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// BatchCall sends all the requests in a single JSON RPC batch and returns the
+// responses in the same order as the requests. Each request gets a new ID so
+// responses can be matched no matter the order the endpoint returns them.
+// JSON RPC errors of individual entries are kept in the Error field of the
+// corresponding response, see Response.Decode.
+func (c *Client) BatchCall(t *testing.T, ctx context.Context, requests []Request) ([]Response, error) {
+	if len(requests) == 0 {
+		return []Response{}, nil
+	}
+
+	positions := make(map[string]int, len(requests))
+	batch := make([]Request, len(requests))
+	for i, request := range requests {
+		request.ID = c.NextID()
+		key, err := idKey(request.ID)
+		if err != nil {
+			return nil, err
+		}
+		positions[key] = i
+		batch[i] = request
+	}
+
+	input, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := c.RawCall(t, ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// endpoints that reject the batch as a whole answer with a single response
+	if trimmed := bytes.TrimSpace(output); len(trimmed) > 0 && trimmed[0] == '{' {
+		var res Response
+		if err := json.Unmarshal(trimmed, &res); err != nil {
+			return nil, err
+		}
+		if res.Error != nil {
+			return nil, res.Error.RPCError()
+		}
+		return nil, fmt.Errorf("unexpected non batch response: %v", string(trimmed))
+	}
+
+	var batchRes []Response
+	err = json.Unmarshal(output, &batchRes)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]Response, len(requests))
+	found := make([]bool, len(requests))
+	for _, res := range batchRes {
+		key, err := idKey(res.ID)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := positions[key]
+		if !ok {
+			return nil, fmt.Errorf("unexpected response id %v in batch", key)
+		}
+		if found[i] {
+			return nil, fmt.Errorf("duplicated response id %v in batch", key)
+		}
+		responses[i] = res
+		found[i] = true
+	}
+
+	for i, ok := range found {
+		if !ok {
+			return nil, fmt.Errorf("missing response for request %v (%v) in batch", batch[i].ID, batch[i].Method)
+		}
+	}
+
+	return responses, nil
+}
+
+// RPCBatchCall sends the requests as a batch to the provided URL using a default Client
+func RPCBatchCall(t *testing.T, url string, requests []Request) ([]Response, error) {
+	return NewClient(url).BatchCall(t, context.Background(), requests)
+}
+
+// idKey normalizes a request or response ID so both can be compared, since
+// numeric IDs are decoded from the responses as float64
+func idKey(id any) (string, error) {
+	b, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchCallMatchesOutOfOrderResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+
+		res := make([]map[string]any, 0, len(reqs))
+		for i := len(reqs) - 1; i >= 0; i-- {
+			if reqs[i].Method == "eth_fail" {
+				res = append(res, map[string]any{"jsonrpc": "2.0", "id": reqs[i].ID, "error": map[string]any{"code": -32000, "message": "failed"}})
+				continue
+			}
+			res = append(res, map[string]any{"jsonrpc": "2.0", "id": reqs[i].ID, "result": reqs[i].Method})
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	responses, err := client.BatchCall(t, context.Background(), []Request{
+		NewRequest("eth_chainId"),
+		NewRequest("eth_fail"),
+		NewRequest("eth_blockNumber"),
+	})
+	require.NoError(t, err)
+	require.Len(t, responses, 3)
+
+	var method string
+	require.NoError(t, responses[0].Decode(&method))
+	assert.Equal(t, "eth_chainId", method)
+
+	err = responses[1].Decode(&method)
+	require.Error(t, err)
+	assert.Equal(t, "failed", err.Error())
+
+	require.NoError(t, responses[2].Decode(&method))
+	assert.Equal(t, "eth_blockNumber", method)
+}
+
+func TestBatchCallMatchesOutOfOrderWebSocketResponses(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for {
+			var reqs []Request
+			if err := conn.ReadJSON(&reqs); err != nil {
+				return
+			}
+			res := make([]map[string]any, 0, len(reqs))
+			for i := len(reqs) - 1; i >= 0; i-- {
+				res = append(res, map[string]any{"jsonrpc": "2.0", "id": reqs[i].ID, "result": reqs[i].Method})
+			}
+			require.NoError(t, conn.WriteJSON(res))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	responses, err := client.BatchCall(t, ctx, []Request{
+		NewRequest("eth_chainId"),
+		NewRequest("eth_blockNumber"),
+	})
+	require.NoError(t, err)
+	require.Len(t, responses, 2)
+
+	var method string
+	require.NoError(t, responses[0].Decode(&method))
+	assert.Equal(t, "eth_chainId", method)
+	require.NoError(t, responses[1].Decode(&method))
+	assert.Equal(t, "eth_blockNumber", method)
+}
+
+func TestBatchCallFailsOnMissingResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		_ = json.NewEncoder(w).Encode([]map[string]any{{"jsonrpc": "2.0", "id": reqs[0].ID, "result": "0x1"}})
+	}))
+	defer server.Close()
+
+	_, err := RPCBatchCall(t, server.URL, []Request{NewRequest("eth_chainId"), NewRequest("eth_blockNumber")})
+	require.ErrorContains(t, err, "missing response")
+}
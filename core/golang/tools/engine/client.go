@@ -0,0 +1,353 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/log"
+)
+
+const (
+	// DefaultRequestTimeout is the timeout applied to each HTTP round trip
+	// when the client is created without a custom http.Client
+	DefaultRequestTimeout = 30 * time.Second
+)
+
+// DefaultRetryPolicy is the retry policy used by clients created without
+// a custom one and by the package level RPC functions
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 250 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+}
+
+// RetryPolicy defines how many times and how often a JSON RPC call is
+// retried when it fails with a transport error or a retryable HTTP status
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values lower than 1 are handled as 1, meaning no retries.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time to wait between two attempts
+	MaxBackoff time.Duration
+	// Multiplier is applied to the backoff after every failed attempt
+	Multiplier float64
+	// RetrySends allows retrying the payloads sending transactions on
+	// transport errors and 5xx statuses. Such a retry may submit the
+	// transaction twice, so by default they are only retried on 429.
+	RetrySends bool
+}
+
+// NoRetry is a retry policy that performs a single attempt
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// backoff returns the time to wait before the given retry, starting at 1
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		d *= p.Multiplier
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && time.Duration(d) > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return time.Duration(d)
+}
+
+// HTTPError is returned when a JSON RPC endpoint answers with a non 200 status
+type HTTPError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error returns the error message.
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%v - %v", e.StatusCode, string(e.Body))
+}
+
+// Retryable reports whether the request that caused the error is worth retrying
+func (e *HTTPError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// Client is a JSON RPC client bound to a single endpoint. Every call accepts
+// a context, failed calls are retried according to the retry policy and each
+// request sent through the client gets a new, incremental ID.
+//
+// Endpoints using the ws or wss scheme are reached through a single
+// WebSocket connection, which also allows to Subscribe to node events.
+type Client struct {
+	url         string
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	lastID      atomic.Uint64
+
+	wsMu sync.Mutex
+	ws   *wsConn
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the http.Client used to send the requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets the timeout applied to each HTTP round trip
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.httpClient = &http.Client{Timeout: timeout}
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed calls
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// NewClient creates a JSON RPC client for the provided URL
+func NewClient(url string, opts ...ClientOption) *Client {
+	c := &Client{
+		url:         url,
+		httpClient:  &http.Client{Timeout: DefaultRequestTimeout},
+		retryPolicy: DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// URL returns the endpoint the client sends the requests to
+func (c *Client) URL() string {
+	return c.url
+}
+
+// SupportsSubscriptions reports whether the endpoint is a WebSocket one
+func (c *Client) SupportsSubscriptions() bool {
+	return isWebSocketURL(c.url)
+}
+
+// Close closes the WebSocket connection, if any
+func (c *Client) Close() error {
+	c.wsMu.Lock()
+	defer c.wsMu.Unlock()
+	if c.ws == nil {
+		return nil
+	}
+	err := c.ws.close()
+	c.ws = nil
+	return err
+}
+
+// NextID returns a new request ID, unique for this client
+func (c *Client) NextID() uint64 {
+	return c.lastID.Add(1)
+}
+
+// Call sends the request to the endpoint, replacing its ID with a new one,
+// and returns the decoded response
+func (c *Client) Call(t *testing.T, ctx context.Context, request Request) (Response, error) {
+	request.ID = c.NextID()
+	input, err := json.Marshal(request)
+	if err != nil {
+		return Response{}, err
+	}
+
+	output, err := c.RawCall(t, ctx, input)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var res Response
+	err = json.Unmarshal(output, &res)
+	if err != nil {
+		return Response{}, err
+	}
+
+	return res, nil
+}
+
+// CallResult sends a request for the given method and params and decodes the
+// result into result. A JSON RPC error in the response is returned as error.
+func (c *Client) CallResult(t *testing.T, ctx context.Context, result any, method string, params ...any) error {
+	res, err := c.Call(t, ctx, NewRequest(method, params...))
+	if err != nil {
+		return err
+	}
+
+	if res.Error == nil && result == nil {
+		return nil
+	}
+
+	return res.Decode(result)
+}
+
+// RawCall sends the raw JSON payload to the endpoint and returns the raw
+// response, retrying transport errors and 5xx/429 statuses. Payloads sending
+// transactions are only retried on 429 unless the policy allows RetrySends.
+func (c *Client) RawCall(t *testing.T, ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	attempts := c.retryPolicy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	idempotent := c.retryPolicy.RetrySends || !sendsTransaction(input)
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var output json.RawMessage
+		output, err = c.send(ctx, input, nil)
+		if err == nil {
+			log.Msgf(t, "RPC call to %v", c.url)
+			log.Complements(t, fmt.Sprintf("request: %v", string(input)), fmt.Sprintf("response: %v", string(output)))
+			return output, nil
+		}
+
+		if attempt == attempts || !isRetryable(ctx, err, idempotent) {
+			break
+		}
+
+		wait := c.retryPolicy.backoff(attempt)
+		log.Msgf(t, "RPC call to %v failed (attempt %d/%d): %v, retrying in %v", c.url, attempt, attempts, err, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	return nil, err
+}
+
+// send delivers the payload through the transport matching the URL scheme
+func (c *Client) send(ctx context.Context, input json.RawMessage, sub *Subscription) (json.RawMessage, error) {
+	if !c.SupportsSubscriptions() {
+		return c.post(ctx, input)
+	}
+
+	conn, err := c.wsConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if sub != nil {
+		sub.conn = conn
+	}
+	return conn.call(ctx, input, sub)
+}
+
+// wsConn returns the WebSocket connection, dialing a new one if there is
+// none yet or the previous one failed
+func (c *Client) wsConn(ctx context.Context) (*wsConn, error) {
+	c.wsMu.Lock()
+	defer c.wsMu.Unlock()
+	if c.ws != nil && !c.ws.failed() {
+		return c.ws, nil
+	}
+
+	conn, err := dialWS(ctx, c.url)
+	if err != nil {
+		return nil, err
+	}
+	c.ws = conn
+	return conn, nil
+}
+
+func (c *Client) post(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Add("Content-type", "application/json")
+
+	httpRes, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	output, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpRes.StatusCode != http.StatusOK {
+		return nil, &HTTPError{StatusCode: httpRes.StatusCode, Body: output}
+	}
+
+	// removes output suffix trailing newline
+	if len(output) > 0 && output[len(output)-1] == 10 {
+		output = output[:len(output)-1]
+	}
+
+	return output, nil
+}
+
+// isRetryable reports whether a failed call can be retried: transport errors
+// and 5xx/429 statuses are, unless the caller's context is already done. A
+// call that is not idempotent is only retried on 429, as the node didn't
+// process it.
+func isRetryable(ctx context.Context, err error, idempotent bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		if !idempotent {
+			return httpErr.StatusCode == http.StatusTooManyRequests
+		}
+		return httpErr.Retryable()
+	}
+
+	return idempotent
+}
+
+// sendsTransaction reports whether the payload, a request or a batch, calls
+// a method submitting a transaction, like eth_sendRawTransaction. Payloads
+// that can't be decoded are handled as sending one.
+func sendsTransaction(input json.RawMessage) bool {
+	var requests []struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(input, &requests); err != nil {
+		var request struct {
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(input, &request); err != nil {
+			return true
+		}
+		requests = append(requests, request)
+	}
+
+	for _, request := range requests {
+		_, name, _ := strings.Cut(request.Method, "_")
+		if strings.HasPrefix(name, "send") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+	Multiplier:     2,
+}
+
+func TestClientRetriesRetryableStatuses(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": "0x1"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryPolicy(testRetryPolicy))
+	var result string
+	err := client.CallResult(t, context.Background(), &result, "eth_chainId")
+	require.NoError(t, err)
+	assert.Equal(t, "0x1", result)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryPolicy(testRetryPolicy))
+	_, err := client.Call(t, context.Background(), NewRequest("eth_chainId"))
+
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClientIncrementsRequestIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": req.ID})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	for i := 1; i <= 3; i++ {
+		res, err := client.Call(t, context.Background(), NewRequest("eth_chainId"))
+		require.NoError(t, err)
+		assert.Equal(t, float64(i), res.ID)
+	}
+}
+
+func TestClientStopsRetryingWhenContextIsDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := NewClient(server.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 100, InitialBackoff: time.Second}))
+	_, err := client.Call(t, ctx, NewRequest("eth_chainId"))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClientDoesNotRetrySends(t *testing.T) {
+	var calls atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusBadGateway)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryPolicy(testRetryPolicy))
+	_, err := client.Call(t, context.Background(), NewRequest("eth_sendRawTransaction", "0x01"))
+	require.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// a rejected send was not processed and is retried
+	calls.Store(0)
+	status.Store(http.StatusTooManyRequests)
+	_, err = client.Call(t, context.Background(), NewRequest("eth_sendRawTransaction", "0x01"))
+	require.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+
+	calls.Store(0)
+	status.Store(http.StatusBadGateway)
+	policy := testRetryPolicy
+	policy.RetrySends = true
+	client = NewClient(server.URL, WithRetryPolicy(policy))
+	_, err = client.RawCall(t, context.Background(), json.RawMessage(`[{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction"}]`))
+	require.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+
+	assert.True(t, sendsTransaction(json.RawMessage(`[{"method":"eth_chainId"},{"method":"eth_sendRawTransaction"}]`)))
+	assert.False(t, sendsTransaction(json.RawMessage(`{"method":"eth_getBalance"}`)))
+}
+
+func TestRPCCallContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := RPCCallContext(t, ctx, server.URL, NewRequest("eth_chainId"))
+	require.ErrorIs(t, err, context.Canceled)
+}
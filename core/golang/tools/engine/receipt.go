@@ -0,0 +1,183 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/hex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// BlockTag is a block tag accepted by the JSON RPC methods
+type BlockTag string
+
+const (
+	LatestBlock    BlockTag = "latest"
+	SafeBlock      BlockTag = "safe"
+	FinalizedBlock BlockTag = "finalized"
+)
+
+// ReceiptStatusError is returned when a tx is mined with a status other
+// than the expected one
+type ReceiptStatusError struct {
+	Receipt        *types.Receipt
+	ExpectedStatus uint64
+}
+
+// Error returns the error message.
+func (e *ReceiptStatusError) Error() string {
+	return fmt.Sprintf("tx %v mined in block %v with status %v, expected status %v",
+		e.Receipt.TxHash.String(), e.Receipt.BlockNumber, e.Receipt.Status, e.ExpectedStatus)
+}
+
+// TransactionReceipt returns the receipt of the tx, or nil if the tx is not mined
+func (c *Client) TransactionReceipt(t *testing.T, ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	err := c.CallResult(t, ctx, &receipt, "eth_getTransactionReceipt", txHash.String())
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// GetBlockRef returns the number and hash of the block with the given tag or
+// number, or nil if the block doesn't exist
+func (c *Client) GetBlockRef(t *testing.T, ctx context.Context, block string) (*BlockRef, error) {
+	var ref *BlockRef
+	err := c.CallResult(t, ctx, &ref, "eth_getBlockByNumber", block, false)
+	if err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// BlockRef identifies a block
+type BlockRef struct {
+	Number string      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// BlockNumber returns the decoded block number
+func (b BlockRef) BlockNumber() uint64 {
+	return hex.DecodeUint64(b.Number)
+}
+
+// ReceiptWait defines the conditions a receipt must meet to stop waiting
+type ReceiptWait struct {
+	// Status, when set, is the status the receipt must have, the wait fails
+	// as soon as the tx is mined with a different one
+	Status *uint64
+	// Confirmations is the minimum number of blocks, including the one with
+	// the tx, that must be on top of the chain
+	Confirmations uint64
+	// Tag, when set, requires the block with the tx to be at or below the
+	// block with this tag, e.g. safe or finalized
+	Tag BlockTag
+	// Interval between two checks, defaults to one second
+	Interval Interval
+}
+
+// WaitReceipt waits until the tx is mined and returns its receipt
+func WaitReceipt(t *testing.T, ctx context.Context, url string, txHash common.Hash, timeout time.Duration) (*types.Receipt, error) {
+	return WaitReceiptWith(t, ctx, NewClient(url), txHash, ReceiptWait{}, timeout)
+}
+
+// WaitSuccessfulReceipt waits until the tx is mined and fails if it reverted
+func WaitSuccessfulReceipt(t *testing.T, ctx context.Context, url string, txHash common.Hash, timeout time.Duration) (*types.Receipt, error) {
+	status := types.ReceiptStatusSuccessful
+	return WaitReceiptWith(t, ctx, NewClient(url), txHash, ReceiptWait{Status: &status}, timeout)
+}
+
+// WaitFailedReceipt waits until the tx is mined and fails if it didn't revert
+func WaitFailedReceipt(t *testing.T, ctx context.Context, url string, txHash common.Hash, timeout time.Duration) (*types.Receipt, error) {
+	status := types.ReceiptStatusFailed
+	return WaitReceiptWith(t, ctx, NewClient(url), txHash, ReceiptWait{Status: &status}, timeout)
+}
+
+// WaitReceiptConfirmations waits until the tx is mined and the given number
+// of blocks, including the one with the tx, are on top of the chain
+func WaitReceiptConfirmations(t *testing.T, ctx context.Context, url string, txHash common.Hash, confirmations uint64, timeout time.Duration) (*types.Receipt, error) {
+	return WaitReceiptWith(t, ctx, NewClient(url), txHash, ReceiptWait{Confirmations: confirmations}, timeout)
+}
+
+// WaitReceiptAtTag waits until the block with the tx is at or below the
+// block with the given tag, e.g. until the tx is finalized
+func WaitReceiptAtTag(t *testing.T, ctx context.Context, url string, txHash common.Hash, tag BlockTag, timeout time.Duration) (*types.Receipt, error) {
+	return WaitReceiptWith(t, ctx, NewClient(url), txHash, ReceiptWait{Tag: tag}, timeout)
+}
+
+// WaitReceiptWith waits until the tx receipt meets all the conditions. The
+// receipt is fetched again on every check, so a tx moved to another block by
+// a reorg is followed.
+func WaitReceiptWith(t *testing.T, ctx context.Context, client *Client, txHash common.Hash, conditions ReceiptWait, timeout time.Duration) (*types.Receipt, error) {
+	interval := conditions.Interval
+	if interval.Initial == 0 {
+		interval = Every(time.Second)
+	}
+
+	description := fmt.Sprintf("receipt of tx %v", txHash.String())
+	if conditions.Confirmations > 0 {
+		description += fmt.Sprintf(" with %v confirmations", conditions.Confirmations)
+	}
+	if conditions.Tag != "" {
+		description += fmt.Sprintf(" at %v block", conditions.Tag)
+	}
+
+	var receipt *types.Receipt
+	err := WaitFor(t, ctx, description, interval, timeout, func(ctx context.Context) (bool, error) {
+		var err error
+		receipt, err = client.TransactionReceipt(t, ctx, txHash)
+		if err != nil {
+			return false, err
+		}
+		if receipt == nil {
+			return false, NotReady("tx %v not mined yet", txHash.String())
+		}
+
+		if conditions.Status != nil && receipt.Status != *conditions.Status {
+			return false, &ReceiptStatusError{Receipt: receipt, ExpectedStatus: *conditions.Status}
+		}
+
+		txBlock := receipt.BlockNumber.Uint64()
+		if conditions.Confirmations > 0 {
+			latest, err := client.BlockNumber(t, ctx)
+			if err != nil {
+				return false, err
+			}
+			if latest < txBlock || latest-txBlock+1 < conditions.Confirmations {
+				return false, NotReady("tx %v mined in block %v, latest block %v", txHash.String(), txBlock, latest)
+			}
+		}
+
+		if conditions.Tag != "" {
+			tagged, err := client.GetBlockRef(t, ctx, string(conditions.Tag))
+			if err != nil {
+				return false, err
+			}
+			if tagged == nil {
+				return false, NotReady("tx %v mined in block %v, no %v block yet", txHash.String(), txBlock, conditions.Tag)
+			}
+			if tagged.BlockNumber() < txBlock {
+				return false, NotReady("tx %v mined in block %v, %v block %v", txHash.String(), txBlock, conditions.Tag, tagged.BlockNumber())
+			}
+		}
+
+		// make sure the block with the tx is still canonical
+		if conditions.Confirmations > 0 || conditions.Tag != "" {
+			canonical, err := client.GetBlockRef(t, ctx, hex.EncodeUint64(txBlock))
+			if err != nil {
+				return false, err
+			}
+			if canonical == nil || canonical.Hash != receipt.BlockHash {
+				return false, NotReady("block %v with tx %v was reorged", txBlock, txHash.String())
+			}
+		}
+
+		return true, nil
+	})
+
+	return receipt, err
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/hex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiptServer serves a receipt mined at block 10 with the given status, the
+// latest block increases on every eth_blockNumber call
+func receiptServer(t *testing.T, status string) *httptest.Server {
+	var latest atomic.Uint64
+	latest.Store(10)
+	blockHash := common.HexToHash("0xb10c")
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		res := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_getTransactionReceipt":
+			res["result"] = map[string]any{
+				"transactionHash":   common.HexToHash("0x1"),
+				"blockHash":         blockHash,
+				"blockNumber":       "0xa",
+				"status":            status,
+				"cumulativeGasUsed": "0x5208",
+				"gasUsed":           "0x5208",
+				"logsBloom":         types.Bloom{},
+				"logs":              []any{},
+			}
+		case "eth_blockNumber":
+			res["result"] = hex.EncodeUint64(latest.Add(1))
+		case "eth_getBlockByNumber":
+			res["result"] = map[string]any{"number": "0xa", "hash": blockHash}
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+}
+
+func TestWaitReceiptConfirmations(t *testing.T) {
+	server := receiptServer(t, "0x1")
+	defer server.Close()
+
+	status := types.ReceiptStatusSuccessful
+	receipt, err := WaitReceiptWith(t, context.Background(), NewClient(server.URL), common.HexToHash("0x1"),
+		ReceiptWait{Status: &status, Confirmations: 4, Interval: Every(time.Millisecond)}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), receipt.BlockNumber.Uint64())
+}
+
+func TestWaitSuccessfulReceiptFailsOnRevert(t *testing.T) {
+	server := receiptServer(t, "0x0")
+	defer server.Close()
+
+	_, err := WaitSuccessfulReceipt(t, context.Background(), server.URL, common.HexToHash("0x1"), time.Second)
+	var statusErr *ReceiptStatusError
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, types.ReceiptStatusFailed, statusErr.Receipt.Status)
+}
@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// RevertErrorName is the name of the error raised by require and revert
+	// with a reason string
+	RevertErrorName = "Error"
+	// PanicErrorName is the name of the error raised by failed assertions,
+	// arithmetic overflows and the like
+	PanicErrorName = "Panic"
+
+	selectorLength = 4
+)
+
+var (
+	// ErrNoRevertData is returned when decoding a revert without data
+	ErrNoRevertData = errors.New("no revert data")
+
+	revertSelector = crypto.Keccak256([]byte("Error(string)"))[:selectorLength]
+	panicSelector  = crypto.Keccak256([]byte("Panic(uint256)"))[:selectorLength]
+)
+
+// Revert is the decoded data of a reverted call
+type Revert struct {
+	// Selector is the 4 bytes identifier of the error
+	Selector [selectorLength]byte
+	// Name is Error for revert reasons, Panic for panics or the name of
+	// the custom error, e.g. AlreadyClaimed
+	Name string
+	// Reason is the revert reason string, the panic description or the
+	// signature of the custom error
+	Reason string
+	// Args are the decoded arguments of the error
+	Args []any
+}
+
+// String returns a human readable description of the revert
+func (r *Revert) String() string {
+	switch {
+	case r.Name == RevertErrorName || r.Name == PanicErrorName:
+		return fmt.Sprintf("%v: %v", r.Name, r.Reason)
+	case len(r.Args) > 0:
+		return fmt.Sprintf("%v %v", r.Reason, r.Args)
+	default:
+		return r.Reason
+	}
+}
+
+// DecodeRevert decodes the revert data returned by a node. Error(string) and
+// Panic(uint256) are always decoded, custom errors are looked up in the
+// provided ABIs.
+func DecodeRevert(data []byte, abis ...*abi.ABI) (*Revert, error) {
+	if len(data) == 0 {
+		return nil, ErrNoRevertData
+	}
+	if len(data) < selectorLength {
+		return nil, fmt.Errorf("revert data too short: %x", data)
+	}
+
+	revert := &Revert{}
+	copy(revert.Selector[:], data[:selectorLength])
+
+	if bytes.Equal(data[:selectorLength], revertSelector) || bytes.Equal(data[:selectorLength], panicSelector) {
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return nil, err
+		}
+		revert.Reason = reason
+		revert.Name = RevertErrorName
+		if bytes.Equal(data[:selectorLength], panicSelector) {
+			revert.Name = PanicErrorName
+		}
+		args, err := abi.Arguments{{Type: revertArgType(revert.Name)}}.Unpack(data[selectorLength:])
+		if err != nil {
+			return nil, err
+		}
+		revert.Args = args
+		return revert, nil
+	}
+
+	for _, contractABI := range abis {
+		if contractABI == nil {
+			continue
+		}
+		abiErr, err := contractABI.ErrorByID(revert.Selector)
+		if err != nil {
+			continue
+		}
+		args, err := abiErr.Inputs.Unpack(data[selectorLength:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack %v: %w", abiErr.Sig, err)
+		}
+		revert.Name = abiErr.Name
+		revert.Reason = abiErr.Sig
+		revert.Args = args
+		return revert, nil
+	}
+
+	return nil, fmt.Errorf("unknown error selector 0x%x", revert.Selector)
+}
+
+func revertArgType(name string) abi.Type {
+	typeName := "string"
+	if name == PanicErrorName {
+		typeName = "uint256"
+	}
+	typ, _ := abi.NewType(typeName, "", nil)
+	return typ
+}
+
+// IsRevert reports whether the error was caused by a reverted execution
+func (e *RPCError) IsRevert() bool {
+	return e.code == ExecutionRevertedErrorCode ||
+		len(e.data) >= selectorLength && strings.Contains(strings.ToLower(e.err), "revert")
+}
+
+// Revert decodes the revert data carried by the error, see DecodeRevert
+func (e *RPCError) Revert(abis ...*abi.ABI) (*Revert, error) {
+	return DecodeRevert(e.data, abis...)
+}
+
+// RevertFromError extracts and decodes the revert data of an RPCError
+// wrapped in err, see DecodeRevert
+func RevertFromError(err error, abis ...*abi.ABI) (*Revert, error) {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		return nil, fmt.Errorf("not a JSON RPC error: %w", err)
+	}
+	return rpcErr.Revert(abis...)
+}
+
+// IsRevertWithError reports whether err is a JSON RPC error carrying a revert
+// with the given error name, e.g. Error, Panic or a custom error like
+// AlreadyClaimed defined in one of the provided ABIs
+func IsRevertWithError(err error, name string, abis ...*abi.ABI) bool {
+	revert, decodeErr := RevertFromError(err, abis...)
+	return decodeErr == nil && revert.Name == name
+}
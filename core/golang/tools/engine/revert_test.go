@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/hex"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bridgeErrorsABI = `[
+	{"inputs":[],"name":"AlreadyClaimed","type":"error"},
+	{"inputs":[],"name":"InvalidSmtProof","type":"error"},
+	{"inputs":[{"internalType":"uint32","name":"depositCount","type":"uint32"}],"name":"InvalidDepositCount","type":"error"}
+]`
+
+func TestRPCErrorWorksWithErrorsAs(t *testing.T) {
+	var res Response
+	err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted","data":"0x646cf558"}}`), &res)
+	require.NoError(t, err)
+
+	wrapped := fmt.Errorf("claim failed: %w", res.Decode(nil))
+
+	var rpcErr *RPCError
+	require.True(t, errors.As(wrapped, &rpcErr))
+	assert.Equal(t, ExecutionRevertedErrorCode, rpcErr.ErrorCode())
+	assert.Equal(t, "execution reverted", rpcErr.ErrorMessage())
+	assert.Equal(t, []byte{0x64, 0x6c, 0xf5, 0x58}, rpcErr.ErrorData())
+	assert.True(t, rpcErr.IsRevert())
+}
+
+func TestDecodeRevert(t *testing.T) {
+	bridgeABI, err := abi.JSON(strings.NewReader(bridgeErrorsABI))
+	require.NoError(t, err)
+
+	stringType, err := abi.NewType("string", "", nil)
+	require.NoError(t, err)
+	uintType, err := abi.NewType("uint256", "", nil)
+	require.NoError(t, err)
+
+	reason, err := abi.Arguments{{Type: stringType}}.Pack("insufficient balance")
+	require.NoError(t, err)
+	panicCode, err := abi.Arguments{{Type: uintType}}.Pack(big.NewInt(0x11))
+	require.NoError(t, err)
+	invalidDepositCount, err := bridgeABI.Errors["InvalidDepositCount"].Inputs.Pack(uint32(7))
+	require.NoError(t, err)
+
+	alreadyClaimed := bridgeABI.Errors["AlreadyClaimed"].ID
+	invalidDepositCountID := bridgeABI.Errors["InvalidDepositCount"].ID
+
+	testCases := []struct {
+		name           string
+		data           []byte
+		expectedName   string
+		expectedReason string
+		expectedArgs   []any
+	}{
+		{"revert reason", append(append([]byte{}, revertSelector...), reason...), RevertErrorName, "insufficient balance", []any{"insufficient balance"}},
+		{"panic", append(append([]byte{}, panicSelector...), panicCode...), PanicErrorName, "arithmetic underflow or overflow", []any{big.NewInt(0x11)}},
+		{"custom error", alreadyClaimed[:4], "AlreadyClaimed", "AlreadyClaimed()", []any{}},
+		{"custom error with args", append(append([]byte{}, invalidDepositCountID[:4]...), invalidDepositCount...), "InvalidDepositCount", "InvalidDepositCount(uint32)", []any{uint32(7)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewRPCErrorWithData(ExecutionRevertedErrorCode, "execution reverted", tc.data)
+
+			revert, decodeErr := RevertFromError(fmt.Errorf("wrapped: %w", err), &bridgeABI)
+			require.NoError(t, decodeErr)
+			assert.Equal(t, tc.expectedName, revert.Name)
+			assert.Equal(t, tc.expectedReason, revert.Reason)
+			assert.Equal(t, tc.expectedArgs, revert.Args)
+			assert.True(t, IsRevertWithError(err, tc.expectedName, &bridgeABI))
+		})
+	}
+}
+
+func TestDecodeRevertUnknownSelector(t *testing.T) {
+	data, err := hex.DecodeHex("0xdeadbeef")
+	require.NoError(t, err)
+
+	_, err = DecodeRevert(data)
+	require.ErrorContains(t, err, "unknown error selector")
+
+	_, err = DecodeRevert(nil)
+	require.ErrorIs(t, err, ErrNoRevertData)
+}
@@ -1,17 +1,14 @@
 package engine
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"math/big"
-	"net/http"
 	"strings"
 	"testing"
 
 	"github.com/agglayer/e2e/core/golang/tools/hex"
-	"github.com/agglayer/e2e/core/golang/tools/log"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -33,7 +30,38 @@ type Response struct {
 	Error   *ErrorObject
 }
 
-// RPCError represents an error returned by a JSON RPC endpoint.
+// Decode unmarshals the result into the provided value, returning the
+// response error instead when the call failed
+func (r Response) Decode(result any) error {
+	if r.Error != nil {
+		return r.Error.RPCError()
+	}
+	return json.Unmarshal(r.Result, result)
+}
+
+// JSON RPC error codes
+const (
+	// ExecutionRevertedErrorCode is the code used by the nodes when a call
+	// or gas estimation reverts, the revert data is set as error data
+	ExecutionRevertedErrorCode = 3
+	// DefaultErrorCode is the code used by the nodes for generic errors
+	DefaultErrorCode         = -32000
+	InvalidRequestErrorCode  = -32600
+	NotFoundErrorCode        = -32601
+	InvalidParamsErrorCode   = -32602
+	InternalErrorCode        = -32603
+	ParserErrorCode          = -32700
+	AccessDeniedErrorCode    = -32800
+	EndpointBlockedErrorCode = -32801
+)
+
+// RPCError represents an error returned by a JSON RPC endpoint. It is
+// always returned as a pointer, so it can be extracted with errors.As:
+//
+//	var rpcErr *engine.RPCError
+//	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == engine.ExecutionRevertedErrorCode {
+//		...
+//	}
 type RPCError struct {
 	err  string
 	code int
@@ -41,7 +69,12 @@ type RPCError struct {
 }
 
 // Error returns the error message.
-func (e RPCError) Error() string {
+func (e *RPCError) Error() string {
+	return e.err
+}
+
+// ErrorMessage returns the error message.
+func (e *RPCError) ErrorMessage() string {
 	return e.err
 }
 
@@ -64,13 +97,12 @@ type ErrorObject struct {
 
 // RPCError returns an instance of RPCError from the
 // data available in the ErrorObject instance
-func (e *ErrorObject) RPCError() RPCError {
+func (e *ErrorObject) RPCError() *RPCError {
 	var data []byte
 	if e.Data != nil {
 		data = *e.Data
 	}
-	rpcError := NewRPCErrorWithData(e.Code, e.Message, data)
-	return *rpcError
+	return NewRPCErrorWithData(e.Code, e.Message, data)
 }
 
 // NewRPCError creates a new error instance to be returned by the RPC endpoints
@@ -97,6 +129,27 @@ func (b ArgBytes) MarshalText() ([]byte, error) {
 	return encodeToHex(b), nil
 }
 
+// UnmarshalJSON decodes hex strings into the bytes they represent. Nodes
+// that return plain text or JSON objects as error data are also supported,
+// in which case the bytes hold the text or the raw JSON respectively.
+func (b *ArgBytes) UnmarshalJSON(input []byte) error {
+	var str string
+	if err := json.Unmarshal(input, &str); err != nil {
+		*b = append((*b)[:0], input...)
+		return nil
+	}
+
+	if strings.HasPrefix(str, "0x") {
+		if decoded, err := hex.DecodeHex(str); err == nil {
+			*b = decoded
+			return nil
+		}
+	}
+
+	*b = []byte(str)
+	return nil
+}
+
 func encodeToHex(b []byte) []byte {
 	str := hex.EncodeToString(b)
 	if len(str)%2 != 0 {
@@ -105,58 +158,27 @@ func encodeToHex(b []byte) []byte {
 	return []byte("0x" + str)
 }
 
+// RPCCall sends the request to the provided URL using a default Client, it
+// can't be cancelled, see RPCCallContext
 func RPCCall(t *testing.T, url string, request Request) (Response, error) {
-	input, err := json.Marshal(request)
-	if err != nil {
-		return Response{}, err
-	}
-
-	output, err := RawJSONRPCCall(t, url, input)
-	if err != nil {
-		return Response{}, err
-	}
-
-	var res Response
-	err = json.Unmarshal(output, &res)
-	if err != nil {
-		return Response{}, err
-	}
+	return RPCCallContext(t, context.Background(), url, request)
+}
 
-	return res, nil
+// RPCCallContext sends the request to the provided URL using a default Client
+func RPCCallContext(t *testing.T, ctx context.Context, url string, request Request) (Response, error) {
+	return NewClient(url).Call(t, ctx, request)
 }
 
+// RawJSONRPCCall sends the raw JSON payload to the provided URL using a
+// default Client, it can't be cancelled, see RawJSONRPCCallContext
 func RawJSONRPCCall(t *testing.T, url string, input json.RawMessage) (json.RawMessage, error) {
-	reqBodyReader := bytes.NewReader(input)
-	httpReq, err := http.NewRequest(http.MethodPost, url, reqBodyReader)
-	if err != nil {
-		return nil, err
-	}
-
-	httpReq.Header.Add("Content-type", "application/json")
-
-	httpRes, err := http.DefaultClient.Do(httpReq)
-	if err != nil {
-		return nil, err
-	}
-
-	output, err := io.ReadAll(httpRes.Body)
-	if err != nil {
-		return nil, err
-	}
-	defer httpRes.Body.Close()
-
-	if httpRes.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%v - %v", httpRes.StatusCode, string(output))
-	}
-
-	// removes output suffix trailing newline
-	if len(output) > 0 && output[len(output)-1] == 10 {
-		output = output[:len(output)-1]
-	}
+	return RawJSONRPCCallContext(t, context.Background(), url, input)
+}
 
-	log.Msgf(t, "RPC call to %v", url)
-	log.Complements(t, fmt.Sprintf("request: %v", string(input)), fmt.Sprintf("response: %v", string(output)))
-	return output, nil
+// RawJSONRPCCallContext sends the raw JSON payload to the provided URL using
+// a default Client
+func RawJSONRPCCallContext(t *testing.T, ctx context.Context, url string, input json.RawMessage) (json.RawMessage, error) {
+	return NewClient(url).RawCall(t, ctx, input)
 }
 
 const (
@@ -210,5 +232,3 @@ func MustGetAuth(privateKeyStr string, chainID uint64) *bind.TransactOpts {
 	}
 	return auth
 }
-
-
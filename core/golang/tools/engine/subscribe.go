@@ -0,0 +1,335 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/hex"
+	"github.com/agglayer/e2e/core/golang/tools/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// DefaultPollInterval is the interval used by the wait helpers when the
+	// endpoint doesn't support subscriptions
+	DefaultPollInterval = time.Second
+)
+
+// LogFilter is the filter of a logs subscription or eth_getLogs query
+type LogFilter struct {
+	Addresses []common.Address `json:"address,omitempty"`
+	Topics    [][]common.Hash  `json:"topics,omitempty"`
+}
+
+// Subscribe opens an eth_subscribe subscription with the given params. If ctx
+// is done before the node answers, the subscription it opens afterwards is
+// cancelled.
+func (c *Client) Subscribe(t *testing.T, ctx context.Context, params ...any) (*Subscription, error) {
+	if !c.SupportsSubscriptions() {
+		return nil, ErrSubscriptionsNotSupported
+	}
+
+	request := NewRequest("eth_subscribe", params...)
+	request.ID = c.NextID()
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := newSubscription(c)
+	output, err := c.send(ctx, input, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Response
+	err = json.Unmarshal(output, &res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, res.Error.RPCError()
+	}
+	if sub.id == "" {
+		return nil, fmt.Errorf("unexpected eth_subscribe result: %v", string(res.Result))
+	}
+
+	log.Msgf(t, "subscribed to %v on %v with id %v", string(request.Params), c.url, sub.id)
+	return sub, nil
+}
+
+// SubscribeNewHeads subscribes to the headers of the new blocks
+func (c *Client) SubscribeNewHeads(t *testing.T, ctx context.Context) (*Subscription, error) {
+	return c.Subscribe(t, ctx, "newHeads")
+}
+
+// SubscribeLogs subscribes to the new logs matching the filter
+func (c *Client) SubscribeLogs(t *testing.T, ctx context.Context, filter LogFilter) (*Subscription, error) {
+	return c.Subscribe(t, ctx, "logs", filter)
+}
+
+// SubscribeNewPendingTransactions subscribes to the hashes of the
+// transactions added to the pool
+func (c *Client) SubscribeNewPendingTransactions(t *testing.T, ctx context.Context) (*Subscription, error) {
+	return c.Subscribe(t, ctx, "newPendingTransactions")
+}
+
+// BlockNumber returns the number of the latest block
+func (c *Client) BlockNumber(t *testing.T, ctx context.Context) (uint64, error) {
+	var result string
+	err := c.CallResult(t, ctx, &result, "eth_blockNumber")
+	if err != nil {
+		return 0, err
+	}
+	return hex.DecodeUint64(result), nil
+}
+
+// WaitNewHeads calls check with the number of the latest block right away and
+// then every time a new block is produced, until check reports it is done,
+// returns an error or the timeout expires. New blocks are notified through a
+// newHeads subscription when the endpoint supports it, otherwise the latest
+// block number is polled every DefaultPollInterval.
+func WaitNewHeads(t *testing.T, ctx context.Context, client *Client, timeout time.Duration, check func(ctx context.Context, blockNumber uint64) (bool, error)) error {
+	innerCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lastBlock, err := client.BlockNumber(t, innerCtx)
+	if err != nil {
+		return err
+	}
+	if done, err := check(innerCtx, lastBlock); err != nil || done {
+		return err
+	}
+
+	var heads <-chan json.RawMessage
+	var subErr <-chan error
+	if client.SupportsSubscriptions() {
+		sub, err := client.SubscribeNewHeads(t, innerCtx)
+		if err != nil {
+			log.Msgf(t, "failed to subscribe to new heads, falling back to polling: %v", err)
+		} else {
+			defer sub.Unsubscribe(t, context.Background()) //nolint:errcheck
+			heads, subErr = sub.Notifications(), sub.Err()
+		}
+	}
+
+	var tick <-chan time.Time
+	if heads == nil {
+		ticker := time.NewTicker(DefaultPollInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		blockNumber := lastBlock
+		select {
+		case <-innerCtx.Done():
+			return innerCtx.Err()
+		case err, ok := <-subErr:
+			if ok && err != nil {
+				return err
+			}
+			return fmt.Errorf("new heads subscription terminated")
+		case head := <-heads:
+			var header struct {
+				Number *hexutil.Big `json:"number"`
+			}
+			if err := json.Unmarshal(head, &header); err != nil {
+				return err
+			}
+			if header.Number == nil {
+				return fmt.Errorf("new head without number: %v", string(head))
+			}
+			blockNumber = (*big.Int)(header.Number).Uint64()
+		case <-tick:
+			blockNumber, err = client.BlockNumber(t, innerCtx)
+			if err != nil {
+				return err
+			}
+		}
+
+		if blockNumber <= lastBlock {
+			continue
+		}
+		lastBlock = blockNumber
+
+		if done, err := check(innerCtx, blockNumber); err != nil || done {
+			return err
+		}
+	}
+}
+
+// WaitBlockNumber waits until the chain reaches the given block number
+func WaitBlockNumber(t *testing.T, ctx context.Context, client *Client, number uint64, timeout time.Duration) error {
+	log.Msgf(t, "waiting block %v", number)
+	return WaitNewHeads(t, ctx, client, timeout, func(ctx context.Context, blockNumber uint64) (bool, error) {
+		return blockNumber >= number, nil
+	})
+}
+
+// WaitTxToBeMinedOnNewHeads waits until a tx has been mined, checking it on
+// every new block instead of on a fixed interval
+func WaitTxToBeMinedOnNewHeads(t *testing.T, ctx context.Context, client *Client, txHash common.Hash, timeout time.Duration) error {
+	log.Msgf(t, "waiting tx %v to be mined", txHash.String())
+	return WaitNewHeads(t, ctx, client, timeout, func(ctx context.Context, blockNumber uint64) (bool, error) {
+		var tx *struct {
+			BlockHash *common.Hash `json:"blockHash"`
+		}
+		err := client.CallResult(t, ctx, &tx, "eth_getTransactionByHash", txHash)
+		if err != nil {
+			return false, err
+		}
+		if tx == nil || tx.BlockHash == nil {
+			log.Msgf(t, "tx %v not mined yet at block %v", txHash.String(), blockNumber)
+			return false, nil
+		}
+		log.Msgf(t, "tx %v was mined", txHash.String())
+		return true, nil
+	})
+}
+
+// WaitPendingTx waits until a tx is known by the node, either in the pool
+// or already mined
+func WaitPendingTx(t *testing.T, ctx context.Context, client *Client, txHash common.Hash, timeout time.Duration) error {
+	log.Msgf(t, "waiting tx %v to reach the pool", txHash.String())
+
+	innerCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var hashes <-chan json.RawMessage
+	var subErr <-chan error
+	if client.SupportsSubscriptions() {
+		sub, err := client.SubscribeNewPendingTransactions(t, innerCtx)
+		if err != nil {
+			log.Msgf(t, "failed to subscribe to pending transactions, falling back to polling: %v", err)
+		} else {
+			defer sub.Unsubscribe(t, context.Background()) //nolint:errcheck
+			hashes, subErr = sub.Notifications(), sub.Err()
+		}
+	}
+
+	// the tx may have reached the node before subscribing
+	known := func() (bool, error) {
+		var tx json.RawMessage
+		err := client.CallResult(t, innerCtx, &tx, "eth_getTransactionByHash", txHash)
+		return err == nil && len(tx) > 0 && string(tx) != "null", err
+	}
+	if found, err := known(); err != nil || found {
+		return err
+	}
+
+	var tick <-chan time.Time
+	if hashes == nil {
+		ticker := time.NewTicker(DefaultPollInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-innerCtx.Done():
+			return innerCtx.Err()
+		case err, ok := <-subErr:
+			if ok && err != nil {
+				return err
+			}
+			return fmt.Errorf("pending transactions subscription terminated")
+		case h := <-hashes:
+			var hash common.Hash
+			if err := json.Unmarshal(h, &hash); err != nil {
+				return err
+			}
+			if hash == txHash {
+				return nil
+			}
+		case <-tick:
+			if found, err := known(); err != nil || found {
+				return err
+			}
+		}
+	}
+}
+
+// WaitLog waits for the first log matching the filter emitted after the
+// call. Logs are received through a logs subscription when the endpoint
+// supports it, otherwise eth_getLogs is polled every DefaultPollInterval.
+func WaitLog(t *testing.T, ctx context.Context, client *Client, filter LogFilter, timeout time.Duration) (*types.Log, error) {
+	innerCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var logs <-chan json.RawMessage
+	var subErr <-chan error
+	if client.SupportsSubscriptions() {
+		sub, err := client.SubscribeLogs(t, innerCtx, filter)
+		if err != nil {
+			log.Msgf(t, "failed to subscribe to logs, falling back to polling: %v", err)
+		} else {
+			defer sub.Unsubscribe(t, context.Background()) //nolint:errcheck
+			logs, subErr = sub.Notifications(), sub.Err()
+		}
+	}
+
+	if logs != nil {
+		for {
+			select {
+			case <-innerCtx.Done():
+				return nil, innerCtx.Err()
+			case err, ok := <-subErr:
+				if ok && err != nil {
+					return nil, err
+				}
+				return nil, fmt.Errorf("logs subscription terminated")
+			case l := <-logs:
+				var ethLog types.Log
+				if err := json.Unmarshal(l, &ethLog); err != nil {
+					return nil, err
+				}
+				if ethLog.Removed {
+					continue
+				}
+				return &ethLog, nil
+			}
+		}
+	}
+
+	latestBlock, err := client.BlockNumber(t, innerCtx)
+	if err != nil {
+		return nil, err
+	}
+	fromBlock := latestBlock + 1
+
+	var found *types.Log
+	err = WaitNewHeads(t, innerCtx, client, timeout, func(ctx context.Context, blockNumber uint64) (bool, error) {
+		if blockNumber < fromBlock {
+			return false, nil
+		}
+		query := map[string]any{
+			"fromBlock": hex.EncodeUint64(fromBlock),
+			"toBlock":   hex.EncodeUint64(blockNumber),
+		}
+		if len(filter.Addresses) > 0 {
+			query["address"] = filter.Addresses
+		}
+		if len(filter.Topics) > 0 {
+			query["topics"] = filter.Topics
+		}
+		var result []types.Log
+		if err := client.CallResult(t, ctx, &result, "eth_getLogs", query); err != nil {
+			return false, err
+		}
+		if len(result) > 0 {
+			found = &result[0]
+			return true, nil
+		}
+		fromBlock = blockNumber + 1
+		return false, nil
+	})
+
+	return found, err
+}
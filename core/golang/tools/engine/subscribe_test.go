@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/hex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// newHeadsServer serves a WebSocket JSON RPC endpoint that notifies three
+// new heads right after a newHeads subscription and reports the tx as mined
+// starting at block 3
+func newHeadsServer(t *testing.T) *httptest.Server {
+	var blockNumber atomic.Uint64
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for {
+			var req Request
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			res := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+			switch req.Method {
+			case "eth_blockNumber":
+				res["result"] = hex.EncodeUint64(blockNumber.Load())
+			case "eth_subscribe":
+				res["result"] = "0xabc"
+			case "eth_unsubscribe":
+				res["result"] = true
+			case "eth_getTransactionByHash":
+				if blockNumber.Load() < 3 {
+					res["result"] = map[string]any{"blockHash": nil}
+				} else {
+					res["result"] = map[string]any{"blockHash": common.HexToHash("0x1")}
+				}
+			}
+			require.NoError(t, conn.WriteJSON(res))
+
+			if req.Method == "eth_subscribe" {
+				for i := 1; i <= 3; i++ {
+					blockNumber.Store(uint64(i))
+					require.NoError(t, conn.WriteJSON(map[string]any{
+						"jsonrpc": "2.0",
+						"method":  "eth_subscription",
+						"params": map[string]any{
+							"subscription": "0xabc",
+							"result":       map[string]any{"number": hex.EncodeUint64(uint64(i))},
+						},
+					}))
+				}
+			}
+		}
+	}))
+}
+
+func TestWaitTxToBeMinedOnNewHeadsSubscription(t *testing.T) {
+	server := newHeadsServer(t)
+	defer server.Close()
+
+	client := NewClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	defer client.Close()
+	require.True(t, client.SupportsSubscriptions())
+
+	err := WaitTxToBeMinedOnNewHeads(t, context.Background(), client, common.HexToHash("0x2"), time.Second)
+	require.NoError(t, err)
+}
+
+func TestWaitBlockNumberFallsBackToPolling(t *testing.T) {
+	var blockNumber atomic.Uint64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": hex.EncodeUint64(blockNumber.Add(1))})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	_, err := client.SubscribeNewHeads(t, context.Background())
+	require.ErrorIs(t, err, ErrSubscriptionsNotSupported)
+
+	err = WaitBlockNumber(t, context.Background(), client, 2, 5*time.Second)
+	require.NoError(t, err)
+}
+
+func TestSubscribeCancelledBeforeReply(t *testing.T) {
+	reply := make(chan struct{})
+	unsubscribed := make(chan json.RawMessage, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var req Request
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			res := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+			switch req.Method {
+			case "eth_subscribe":
+				// the subscription is opened once the caller gave up
+				<-reply
+				res["result"] = "0xabc"
+			case "eth_unsubscribe":
+				unsubscribed <- req.Params
+				res["result"] = true
+			}
+			if err := conn.WriteJSON(res); err != nil {
+				t.Errorf("write failed: %v", err)
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.SubscribeNewHeads(t, ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	close(reply)
+
+	select {
+	case params := <-unsubscribed:
+		require.JSONEq(t, `["0xabc"]`, string(params))
+	case <-time.After(time.Second):
+		t.Fatal("the subscription opened after the timeout was not cancelled")
+	}
+}
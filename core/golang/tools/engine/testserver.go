@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHandler answers the params of a request sent to a test server, an
+// error is sent back as the JSON RPC error of the response
+type TestHandler func(params json.RawMessage) (any, error)
+
+// NewTestServer starts a JSON RPC server answering each method with its
+// handler, e.g. to fake a node in the tests of a typed client. The handlers
+// don't run in the test goroutine, so invalid requests and unexpected methods
+// are reported with t.Errorf and answered with an error. The server is closed
+// with the test.
+func NewTestServer(t *testing.T, handlers map[string]TestHandler) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		handler, found := handlers[req.Method]
+		if !found {
+			t.Errorf("unexpected method %v", req.Method)
+			res["error"] = ErrorObject{Code: NotFoundErrorCode, Message: "method not found"}
+			_ = json.NewEncoder(w).Encode(res)
+			return
+		}
+
+		result, err := handler(req.Params)
+		var rpcErr *RPCError
+		switch {
+		case errors.As(err, &rpcErr):
+			errorObject := ErrorObject{Code: rpcErr.ErrorCode(), Message: rpcErr.ErrorMessage()}
+			if data := rpcErr.ErrorData(); data != nil {
+				errorObject.Data = (*ArgBytes)(&data)
+			}
+			res["error"] = errorObject
+		case err != nil:
+			res["error"] = ErrorObject{Code: DefaultErrorCode, Message: err.Error()}
+		default:
+			res["result"] = result
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
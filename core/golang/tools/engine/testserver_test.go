@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestServer(t *testing.T) {
+	ctx := context.Background()
+	server := NewTestServer(t, map[string]TestHandler{
+		"eth_chainId": func(json.RawMessage) (any, error) { return "0x1", nil },
+		"eth_getBalance": func(params json.RawMessage) (any, error) {
+			var list []string
+			if err := json.Unmarshal(params, &list); err != nil {
+				return nil, err
+			}
+			return list[0], nil
+		},
+		"eth_call": func(json.RawMessage) (any, error) {
+			return nil, NewRPCErrorWithData(ExecutionRevertedErrorCode, "execution reverted", []byte{0x1})
+		},
+		"eth_sendRawTransaction": func(json.RawMessage) (any, error) { return nil, errors.New("nonce too low") },
+	})
+	client := NewClient(server.URL)
+
+	var result string
+	require.NoError(t, client.CallResult(t, ctx, &result, "eth_chainId"))
+	assert.Equal(t, "0x1", result)
+	require.NoError(t, client.CallResult(t, ctx, &result, "eth_getBalance", "0xde57", "latest"))
+	assert.Equal(t, "0xde57", result)
+
+	var rpcErr *RPCError
+	require.ErrorAs(t, client.CallResult(t, ctx, &result, "eth_call"), &rpcErr)
+	assert.Equal(t, ExecutionRevertedErrorCode, rpcErr.ErrorCode())
+	assert.Equal(t, []byte{0x1}, rpcErr.ErrorData())
+	require.ErrorAs(t, client.CallResult(t, ctx, &result, "eth_sendRawTransaction"), &rpcErr)
+	assert.Equal(t, DefaultErrorCode, rpcErr.ErrorCode())
+	assert.Equal(t, "nonce too low", rpcErr.ErrorMessage())
+}
@@ -0,0 +1,219 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/hex"
+	"github.com/agglayer/e2e/core/golang/tools/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// maxBatchSize is the maximum number of requests sent in a single batch,
+	// some nodes reject bigger batches
+	maxBatchSize = 100
+	// maxConcurrentBatches is the maximum number of batches in flight
+	maxConcurrentBatches = 4
+	// droppedAfterMisses is the number of checks in a row a seen tx must be
+	// missing to be dropped, as a load balanced endpoint may answer from a
+	// node that doesn't know it yet
+	droppedAfterMisses = 3
+)
+
+// TxsPollInterval is the time between two checks of WaitTxsToBeMined
+var TxsPollInterval = time.Second
+
+// TxOutcome is the final state of a tx waited by WaitTxsToBeMined
+type TxOutcome int
+
+const (
+	// TxTimedOut means the tx was still pending or unknown when the
+	// timeout expired
+	TxTimedOut TxOutcome = iota
+	// TxMined means the tx was included in a block
+	TxMined
+	// TxDropped means the tx was seen in the pool and then went missing
+	// for droppedAfterMisses checks in a row without being mined
+	TxDropped
+)
+
+// String returns the outcome name
+func (o TxOutcome) String() string {
+	switch o {
+	case TxMined:
+		return "mined"
+	case TxDropped:
+		return "dropped"
+	default:
+		return "timed out"
+	}
+}
+
+// TxResult is the outcome of a tx waited by WaitTxsToBeMined
+type TxResult struct {
+	Hash        common.Hash
+	Outcome     TxOutcome
+	BlockNumber uint64
+	BlockHash   common.Hash
+	// Seen reports whether the node knew the tx at some point
+	Seen bool
+	// Err is the last error returned by the node for this tx, if any
+	Err error
+
+	// missing counts the checks in a row the seen tx was unknown
+	missing int
+}
+
+// WaitTxsToBeMined waits until all the txs are mined, dropped or the timeout
+// expires. On every check the pending txs are queried with batch calls of at
+// most maxBatchSize requests, sending up to maxConcurrentBatches at a time.
+// A batch failing with a transport error or a retryable status is checked
+// again on the next round. The results are returned in the same order as the
+// hashes, along with an error aggregating all the txs that were not mined.
+func WaitTxsToBeMined(t *testing.T, ctx context.Context, url string, hashes []common.Hash, timeout time.Duration) ([]TxResult, error) {
+	client := NewClient(url)
+
+	results := make([]TxResult, len(hashes))
+	pending := make([]int, 0, len(hashes))
+	for i, hash := range hashes {
+		results[i] = TxResult{Hash: hash, Outcome: TxTimedOut}
+		pending = append(pending, i)
+	}
+
+	description := fmt.Sprintf("%v txs to be mined", len(hashes))
+	err := WaitFor(t, ctx, description, Every(TxsPollInterval), timeout, func(ctx context.Context) (bool, error) {
+		var err error
+		pending, err = checkTxs(t, ctx, client, results, pending)
+		if err != nil {
+			return false, err
+		}
+		if len(pending) > 0 {
+			return false, NotReady("%v of %v txs still pending", len(pending), len(hashes))
+		}
+		return true, nil
+	})
+
+	var timeoutErr *WaitTimeoutError
+	if err != nil && !errors.As(err, &timeoutErr) {
+		return results, err
+	}
+
+	var errs []error
+	for _, result := range results {
+		switch result.Outcome {
+		case TxMined:
+			continue
+		case TxDropped:
+			errs = append(errs, fmt.Errorf("tx %v was dropped", result.Hash.String()))
+		default:
+			errs = append(errs, fmt.Errorf("tx %v not mined before timeout (seen: %v): %w", result.Hash.String(), result.Seen, errors.Join(result.Err, context.DeadlineExceeded)))
+		}
+	}
+
+	if len(errs) == 0 {
+		log.Msgf(t, "all %v txs were mined", len(hashes))
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// checkTxs queries the pending txs, updates their results and returns the
+// indexes of the txs that are still pending
+func checkTxs(t *testing.T, ctx context.Context, client *Client, results []TxResult, pending []int) ([]int, error) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, maxConcurrentBatches)
+	)
+
+	for start := 0; start < len(pending); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		chunk := pending[start:end]
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			requests := make([]Request, len(chunk))
+			for i, idx := range chunk {
+				requests[i] = NewRequest("eth_getTransactionByHash", results[idx].Hash.String())
+			}
+
+			responses, err := client.BatchCall(t, ctx, requests)
+			if err != nil && isRetryable(ctx, err, true) {
+				// each goroutine updates its own results, no lock needed
+				for _, idx := range chunk {
+					results[idx].Err = err
+				}
+				return
+			}
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+
+			// each goroutine updates its own results, no lock needed
+			for i, idx := range chunk {
+				updateTxResult(&results[idx], responses[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return pending, firstErr
+	}
+
+	stillPending := pending[:0]
+	for _, idx := range pending {
+		if results[idx].Outcome == TxTimedOut {
+			stillPending = append(stillPending, idx)
+		}
+	}
+	return stillPending, nil
+}
+
+func updateTxResult(result *TxResult, res Response) {
+	var tx *struct {
+		BlockNumber *string      `json:"blockNumber"`
+		BlockHash   *common.Hash `json:"blockHash"`
+	}
+	if err := res.Decode(&tx); err != nil {
+		result.Err = err
+		return
+	}
+	result.Err = nil
+
+	switch {
+	case tx == nil && result.Seen:
+		result.missing++
+		if result.missing >= droppedAfterMisses {
+			result.Outcome = TxDropped
+		}
+	case tx == nil:
+	case tx.BlockHash == nil || tx.BlockNumber == nil:
+		result.Seen = true
+		result.missing = 0
+	default:
+		result.Seen = true
+		result.missing = 0
+		result.Outcome = TxMined
+		result.BlockHash = *tx.BlockHash
+		result.BlockNumber = hex.DecodeUint64(*tx.BlockNumber)
+	}
+}
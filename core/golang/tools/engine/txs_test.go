@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitTxsToBeMined(t *testing.T) {
+	previousInterval, previousPolicy := TxsPollInterval, DefaultRetryPolicy
+	TxsPollInterval, DefaultRetryPolicy = 10*time.Millisecond, NoRetry
+	t.Cleanup(func() { TxsPollInterval, DefaultRetryPolicy = previousInterval, previousPolicy })
+
+	mined := common.HexToHash("0x1")
+	dropped := common.HexToHash("0x2")
+	unknown := common.HexToHash("0x3")
+	flaky := common.HexToHash("0x4")
+
+	var batches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		round := batches.Add(1)
+		// a failing batch is checked again on the next round
+		if round == 2 { //nolint:mnd
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var reqs []Request
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("invalid batch: %v", err)
+			return
+		}
+		res := make([]map[string]any, 0, len(reqs))
+		for _, req := range reqs {
+			var params []common.Hash
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				t.Errorf("invalid params: %v", err)
+				return
+			}
+			inPool := map[string]any{"blockNumber": nil, "blockHash": nil}
+			var result any
+			switch {
+			case params[0] == mined:
+				result = map[string]any{"blockNumber": "0x10", "blockHash": common.HexToHash("0xb10c")}
+			case params[0] == dropped && round == 1:
+				result = inPool
+			// the flaky tx is missing once before being mined
+			case params[0] == flaky && (round == 1 || round == 4): //nolint:mnd
+				result = inPool
+			case params[0] == flaky && round > 5: //nolint:mnd
+				result = map[string]any{"blockNumber": "0x11", "blockHash": common.HexToHash("0xb11c")}
+			}
+			res = append(res, map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	results, err := WaitTxsToBeMined(t, context.Background(), server.URL, []common.Hash{mined, dropped, unknown, flaky}, 500*time.Millisecond)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	require.Len(t, results, 4)
+	assert.Equal(t, TxMined, results[0].Outcome)
+	assert.Equal(t, uint64(16), results[0].BlockNumber)
+	assert.Equal(t, TxDropped, results[1].Outcome)
+	assert.Equal(t, TxTimedOut, results[2].Outcome)
+	assert.False(t, results[2].Seen)
+	assert.NoError(t, results[2].Err)
+	assert.Equal(t, TxMined, results[3].Outcome)
+	assert.Equal(t, uint64(17), results[3].BlockNumber)
+}
+
+func TestWaitTxsToBeMinedBatchErrors(t *testing.T) {
+	previousInterval, previousPolicy := TxsPollInterval, DefaultRetryPolicy
+	TxsPollInterval, DefaultRetryPolicy = 10*time.Millisecond, NoRetry
+	t.Cleanup(func() { TxsPollInterval, DefaultRetryPolicy = previousInterval, previousPolicy })
+
+	// a rejected batch stops the wait
+	var batches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batches.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := WaitTxsToBeMined(t, context.Background(), server.URL, []common.Hash{common.HexToHash("0x1")}, time.Second)
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+	assert.Equal(t, int32(1), batches.Load())
+}
@@ -2,9 +2,9 @@ package engine
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -13,155 +13,194 @@ import (
 )
 
 const (
-	TimeoutTxToBeMined    = 30 * time.Second
+	TimeoutTxToBeMined   = 30 * time.Second
 	TimeoutTxToDisappear = 5 * time.Minute
-
-	jsonRPC_Version = "2.0"
-	jsonRPC_ID      = 1
 )
 
-// WaitTxToBeMined waits until a tx has been mined or the given timeout expires.
-func WaitTxToBeMined(t *testing.T, ctx context.Context, url string, txHash common.Hash, timeout time.Duration) error {
-	log.Msgf(t, "waiting tx %v to be mined", txHash.String())
+// Interval defines the time between two checks of a WaitFor condition
+type Interval struct {
+	// Initial is the time to wait after the first check
+	Initial time.Duration
+	// Max caps the interval when it grows exponentially
+	Max time.Duration
+	// Multiplier is applied to the interval after every check, values
+	// lower or equal to 1 keep the interval constant
+	Multiplier float64
+	// Jitter randomizes each interval by up to this fraction of it, e.g.
+	// 0.1 waits between 90% and 110% of the interval
+	Jitter float64
+}
 
-	innerCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+// Every returns a constant interval
+func Every(d time.Duration) Interval {
+	return Interval{Initial: d}
+}
 
-	queryTicker := time.NewTicker(time.Second)
-	defer queryTicker.Stop()
+// Exponential returns an interval that doubles after every check up to max
+func Exponential(initial, max time.Duration) Interval {
+	return Interval{Initial: initial, Max: max, Multiplier: 2} //nolint:mnd
+}
 
-	for {
-		params := []interface{}{txHash.String()}
-		bParams, err := json.Marshal(params)
-		if err != nil {
-			return err
-		}
-		req := Request{
-			JSONRPC: jsonRPC_Version,
-			ID:      jsonRPC_ID,
-			Method:  "eth_getTransactionByHash",
-			Params:  bParams,
-		}
-		res, err := RPCCall(t, url, req)
-		if err != nil {
-			return err
-		}
+// WithJitter returns a copy of the interval randomized by the given fraction
+func (i Interval) WithJitter(jitter float64) Interval {
+	i.Jitter = jitter
+	return i
+}
 
-		if res.Error != nil {
-			return res.Error.RPCError()
+// next returns the time to wait after the given check, starting at 1
+func (i Interval) next(check int) time.Duration {
+	d := float64(i.Initial)
+	if i.Multiplier > 1 {
+		for n := 1; n < check; n++ {
+			d *= i.Multiplier
+			if i.Max > 0 && d >= float64(i.Max) {
+				d = float64(i.Max)
+				break
+			}
 		}
+	}
+	if i.Jitter > 0 {
+		d += d * i.Jitter * (2*rand.Float64() - 1) //nolint:gosec,mnd
+	}
+	if d < 0 {
+		return 0
+	}
+	return time.Duration(d)
+}
 
-		b, err := json.Marshal(res.Result)
-		if err != nil {
-			return err
-		}
-		result := string(b)
-
-		if result == "null" {
-			log.Msgf(t, "tx %v not mined yet", txHash.String())
-		} else {
-			var m map[string]any
-			err := json.Unmarshal(b, &m)
-			if err != nil {
-				log.Msgf(t, "error checking if tx %v was mined or not: %v", txHash.String(), err.Error())
-				return err
-			}
-			if m["blockHash"] != nil {
-				log.Msgf(t, "tx %v was mined", txHash.String())
-				return nil
-			} else {
-				log.Msgf(t, "tx %v not mined yet", txHash.String())
-			}
+// NotReadyError is returned by WaitFor conditions that are not met yet to
+// describe the state they observed, so it is logged and reported if the
+// wait times out
+type NotReadyError struct {
+	State string
+}
+
+// Error returns the observed state.
+func (e *NotReadyError) Error() string {
+	return e.State
+}
+
+// NotReady returns a NotReadyError describing the observed state
+func NotReady(format string, args ...any) error {
+	return &NotReadyError{State: fmt.Sprintf(format, args...)}
+}
+
+// WaitTimeoutError is returned by WaitFor when the condition is not met
+// before the timeout expires. It wraps context.DeadlineExceeded.
+type WaitTimeoutError struct {
+	Description string
+	Timeout     time.Duration
+	// LastState is the state reported by the last check, if any
+	LastState string
+}
+
+// Error returns the error message.
+func (e *WaitTimeoutError) Error() string {
+	msg := fmt.Sprintf("timeout of %v expired waiting for %v", e.Timeout, e.Description)
+	if e.LastState != "" {
+		msg += fmt.Sprintf(", last state: %v", e.LastState)
+	}
+	return msg + ": " + context.DeadlineExceeded.Error()
+}
+
+// Unwrap returns context.DeadlineExceeded.
+func (e *WaitTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// WaitFor checks the condition right away and then after every interval
+// until it is done, it fails or the timeout expires. The condition keeps
+// being checked while it returns false and either no error or an error
+// created with NotReady, any other error stops the wait and is returned
+// wrapped. When the timeout expires a WaitTimeoutError with the last
+// observed state is returned.
+func WaitFor(t *testing.T, ctx context.Context, description string, interval Interval, timeout time.Duration, condition func(ctx context.Context) (bool, error)) error {
+	log.Msgf(t, "waiting for %v", description)
+
+	innerCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lastState := ""
+	for check := 1; ; check++ {
+		done, err := condition(innerCtx)
+
+		var notReady *NotReadyError
+		switch {
+		case errors.As(err, &notReady):
+			lastState = notReady.State
+			log.Msgf(t, "%v: %v", description, lastState)
+		case err != nil && innerCtx.Err() == nil:
+			log.Msgf(t, "error waiting for %v: %v", description, err)
+			return fmt.Errorf("error waiting for %v: %w", description, err)
+		case err == nil && done:
+			log.Msgf(t, "done waiting for %v", description)
+			return nil
 		}
 
+		timer := time.NewTimer(interval.next(check))
 		select {
 		case <-innerCtx.Done():
+			timer.Stop()
 			err := innerCtx.Err()
-			if errors.Is(err, context.DeadlineExceeded) {
-				log.Msgf(t, "stopped waiting tx %v to be mined, timeout expired", txHash)
-				return err
-			} else if err != nil {
-				log.Msgf(t, "error waiting tx %v to be mined: %v", txHash, err)
-				return err
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				log.Msgf(t, "stopped waiting for %v, timeout expired", description)
+				return &WaitTimeoutError{Description: description, Timeout: timeout, LastState: lastState}
 			}
-			log.Msgf(t, "stopped waiting tx %v to be mined, the context is done without errors", txHash)
+			log.Msgf(t, "stopped waiting for %v: %v", description, err)
 			return err
-		case <-queryTicker.C:
+		case <-timer.C:
 		}
 	}
 }
 
-// WaitTxToDisappearByHash waits until a not mined TX that was sent to the network and is still in the pool to disappear
-// from the pool after being discarded during the selection phase. This is mainly used to test zkCounter offenders.
-func WaitTxToDisappearByHash(t *testing.T, ctx context.Context, url string, txHash common.Hash, timeout time.Duration) error {
-	log.Msgf(t, "waiting tx %v to disappear", txHash.String())
-
-	innerCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+// getTransactionByHash returns the raw eth_getTransactionByHash result,
+// which is null when the tx is unknown
+func getTransactionByHash(t *testing.T, ctx context.Context, client *Client, txHash common.Hash) (map[string]any, error) {
+	var tx map[string]any
+	err := client.CallResult(t, ctx, &tx, "eth_getTransactionByHash", txHash.String())
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
 
-	queryTicker := time.NewTicker(10 * time.Second)
-	defer queryTicker.Stop()
+// WaitTxToBeMined waits until a tx has been mined or the given timeout expires.
+func WaitTxToBeMined(t *testing.T, ctx context.Context, url string, txHash common.Hash, timeout time.Duration) error {
+	client := NewClient(url)
+	description := fmt.Sprintf("tx %v to be mined", txHash.String())
 
-	for {
-		params := []interface{}{txHash.String()}
-		bParams, err := json.Marshal(params)
+	return WaitFor(t, ctx, description, Every(time.Second), timeout, func(ctx context.Context) (bool, error) {
+		tx, err := getTransactionByHash(t, ctx, client, txHash)
 		if err != nil {
-			return err
+			return false, err
 		}
-		req := Request{
-			JSONRPC: jsonRPC_Version,
-			ID:      jsonRPC_ID,
-			Method:  "eth_getTransactionByHash",
-			Params:  bParams,
+		if tx == nil {
+			return false, NotReady("tx %v not found", txHash.String())
 		}
-		res, err := RPCCall(t, url, req)
-		if err != nil {
-			return err
+		if tx["blockHash"] == nil {
+			return false, NotReady("tx %v not mined yet", txHash.String())
 		}
+		return true, nil
+	})
+}
 
-		if res.Error != nil {
-			return res.Error.RPCError()
-		}
+// WaitTxToDisappearByHash waits until a not mined TX that was sent to the network and is still in the pool to disappear
+// from the pool after being discarded during the selection phase. This is mainly used to test zkCounter offenders.
+func WaitTxToDisappearByHash(t *testing.T, ctx context.Context, url string, txHash common.Hash, timeout time.Duration) error {
+	client := NewClient(url)
+	description := fmt.Sprintf("tx %v to disappear", txHash.String())
 
-		b, err := json.Marshal(res.Result)
+	return WaitFor(t, ctx, description, Every(10*time.Second), timeout, func(ctx context.Context) (bool, error) { //nolint:mnd
+		tx, err := getTransactionByHash(t, ctx, client, txHash)
 		if err != nil {
-			return err
+			return false, err
 		}
-		result := string(b)
-
-		if result == "null" {
-			log.Msg(t, "transaction disappeared: ", txHash)
-			return nil
-		} else {
-			var m map[string]any
-			err := json.Unmarshal(b, &m)
-			if err != nil {
-				log.Msgf(t, "error checking if tx %v was mined or not: %v", txHash.String(), err.Error())
-				return err
-			}
-			if m["blockHash"] != nil {
-				eMsg := fmt.Sprintf("tx %v was mined and will never disappear", txHash.String())
-				log.Msgf(t, eMsg)
-				return fmt.Errorf(eMsg)
-			}
-
-			log.Msgf(t, "tx %v still exists", txHash.String())
+		if tx == nil {
+			return true, nil
 		}
-
-		select {
-		case <-innerCtx.Done():
-			err := innerCtx.Err()
-			if errors.Is(err, context.DeadlineExceeded) {
-				log.Msgf(t, "stopped waiting tx %v to disappear, timeout expired", txHash)
-				return err
-			} else if err != nil {
-				log.Msgf(t, "error waiting tx %v to disappear: %v", txHash, err)
-				return err
-			}
-			log.Msgf(t, "stopped waiting tx %v to disappear, the context is done without errors", txHash)
-			return err
-		case <-queryTicker.C:
+		if tx["blockHash"] != nil {
+			return false, fmt.Errorf("tx %v was mined and will never disappear", txHash.String())
 		}
-	}
+		return false, NotReady("tx %v still exists", txHash.String())
+	})
 }
-
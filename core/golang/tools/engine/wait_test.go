@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitFor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("done", func(t *testing.T) {
+		checks := 0
+		err := WaitFor(t, ctx, "third check", Every(time.Millisecond), time.Second, func(ctx context.Context) (bool, error) {
+			checks++
+			return checks == 3, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, checks)
+	})
+
+	t.Run("timeout reports last state", func(t *testing.T) {
+		err := WaitFor(t, ctx, "never", Every(time.Millisecond), 20*time.Millisecond, func(ctx context.Context) (bool, error) {
+			return false, NotReady("status %v", "Pending")
+		})
+		var timeoutErr *WaitTimeoutError
+		require.True(t, errors.As(err, &timeoutErr))
+		assert.Equal(t, "status Pending", timeoutErr.LastState)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("condition error stops waiting", func(t *testing.T) {
+		expected := errors.New("boom")
+		err := WaitFor(t, ctx, "failure", Every(time.Millisecond), time.Second, func(ctx context.Context) (bool, error) {
+			return false, expected
+		})
+		assert.ErrorIs(t, err, expected)
+	})
+}
+
+func TestIntervalNext(t *testing.T) {
+	i := Exponential(time.Second, 5*time.Second)
+	assert.Equal(t, time.Second, i.next(1))
+	assert.Equal(t, 2*time.Second, i.next(2))
+	assert.Equal(t, 4*time.Second, i.next(3))
+	assert.Equal(t, 5*time.Second, i.next(4))
+
+	jittered := Every(time.Second).WithJitter(0.1)
+	for n := 1; n < 100; n++ {
+		d := jittered.next(n)
+		assert.GreaterOrEqual(t, d, 900*time.Millisecond)
+		assert.LessOrEqual(t, d, 1100*time.Millisecond)
+	}
+}
@@ -0,0 +1,370 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// subscriptionBufferSize is the number of notifications a subscription
+	// keeps before it is dropped for not being consumed fast enough
+	subscriptionBufferSize = 256
+)
+
+var (
+	// ErrSubscriptionsNotSupported is returned when subscribing through a
+	// client whose endpoint is not a WebSocket one
+	ErrSubscriptionsNotSupported = errors.New("subscriptions require a websocket endpoint")
+
+	// ErrSubscriptionQueueFull is delivered through Subscription.Err when the
+	// notifications are not consumed fast enough
+	ErrSubscriptionQueueFull = errors.New("subscription notifications queue is full")
+)
+
+// isWebSocketURL reports whether the URL uses the ws or wss scheme
+func isWebSocketURL(url string) bool {
+	return strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://")
+}
+
+// wsConn multiplexes JSON RPC calls and subscription notifications over a
+// single WebSocket connection
+type wsConn struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]*wsPending
+	subs    map[string]*Subscription
+	err     error
+	done    chan struct{}
+}
+
+// wsPending is a call waiting for its response
+type wsPending struct {
+	res chan json.RawMessage
+	// sub is registered as soon as the response to an eth_subscribe call is
+	// read, so no notification sent right after it is lost
+	sub *Subscription
+}
+
+func dialWS(ctx context.Context, url string) (*wsConn, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &wsConn{
+		conn:    conn,
+		pending: make(map[string]*wsPending),
+		subs:    make(map[string]*Subscription),
+		done:    make(chan struct{}),
+	}
+	go w.readLoop()
+
+	return w, nil
+}
+
+// call writes the payload, which can be a single request or a batch, and
+// waits for the response matching its ID, or the set of IDs of the batch
+func (w *wsConn) call(ctx context.Context, input json.RawMessage, sub *Subscription) (json.RawMessage, error) {
+	key, err := payloadIDKey(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &wsPending{res: make(chan json.RawMessage, 1), sub: sub}
+	w.mu.Lock()
+	if w.err != nil {
+		w.mu.Unlock()
+		return nil, w.err
+	}
+	w.pending[key] = p
+	w.mu.Unlock()
+
+	w.writeMu.Lock()
+	err = w.conn.WriteMessage(websocket.TextMessage, input)
+	w.writeMu.Unlock()
+	if err != nil {
+		w.removePending(key)
+		return nil, err
+	}
+
+	select {
+	case output := <-p.res:
+		return output, nil
+	case <-ctx.Done():
+		if sub != nil {
+			// the node may still open the subscription after the caller
+			// gave up, it is cancelled as soon as its ID arrives
+			go w.unsubscribeAbandoned(key, p)
+		} else {
+			w.removePending(key)
+		}
+		return nil, ctx.Err()
+	case <-w.done:
+		return nil, w.closeErr()
+	}
+}
+
+// unsubscribeAbandoned waits for the response to an eth_subscribe call whose
+// caller gave up and cancels the subscription the node opened, so it doesn't
+// leak on the node
+func (w *wsConn) unsubscribeAbandoned(key string, p *wsPending) {
+	select {
+	case <-p.res:
+	case <-w.done:
+		return
+	case <-time.After(DefaultRequestTimeout):
+		w.removePending(key)
+		return
+	}
+
+	sub := p.sub
+	if sub.id == "" {
+		return
+	}
+	w.removeSubscription(sub.id)
+	sub.terminate(nil)
+
+	request := NewRequest("eth_unsubscribe", sub.id)
+	request.ID = sub.client.NextID()
+	input, err := json.Marshal(request)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultRequestTimeout)
+	defer cancel()
+	_, _ = w.call(ctx, input, nil)
+}
+
+func (w *wsConn) removePending(key string) {
+	w.mu.Lock()
+	delete(w.pending, key)
+	w.mu.Unlock()
+}
+
+func (w *wsConn) removeSubscription(id string) {
+	w.mu.Lock()
+	delete(w.subs, id)
+	w.mu.Unlock()
+}
+
+func (w *wsConn) closeErr() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// failed reports whether the connection can no longer be used
+func (w *wsConn) failed() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *wsConn) close() error {
+	err := w.conn.Close()
+	w.fail(errors.New("websocket connection closed"))
+	return err
+}
+
+// fail terminates the connection, all the pending calls and subscriptions
+func (w *wsConn) fail(err error) {
+	w.mu.Lock()
+	if w.err != nil {
+		w.mu.Unlock()
+		return
+	}
+	w.err = err
+	subs := w.subs
+	w.subs = make(map[string]*Subscription)
+	w.pending = make(map[string]*wsPending)
+	close(w.done)
+	w.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.terminate(err)
+	}
+}
+
+func (w *wsConn) readLoop() {
+	for {
+		_, msg, err := w.conn.ReadMessage()
+		if err != nil {
+			w.fail(err)
+			return
+		}
+		w.dispatch(msg)
+	}
+}
+
+// wsMessage holds the fields needed to route an incoming message
+type wsMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Params struct {
+		Subscription string          `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+func (w *wsConn) dispatch(msg []byte) {
+	key, err := payloadIDKey(msg)
+	if err != nil {
+		return
+	}
+
+	var m wsMessage
+	if !isBatch(msg) {
+		if err := json.Unmarshal(msg, &m); err != nil {
+			return
+		}
+	}
+
+	if m.Method == "eth_subscription" {
+		w.mu.Lock()
+		sub, found := w.subs[m.Params.Subscription]
+		w.mu.Unlock()
+		if found && !sub.deliver(m.Params.Result) {
+			w.removeSubscription(sub.id)
+			sub.terminate(ErrSubscriptionQueueFull)
+		}
+		return
+	}
+
+	w.mu.Lock()
+	p, found := w.pending[key]
+	delete(w.pending, key)
+	if found && p.sub != nil {
+		var id string
+		if err := json.Unmarshal(m.Result, &id); err == nil && id != "" {
+			p.sub.id = id
+			w.subs[id] = p.sub
+		}
+	}
+	w.mu.Unlock()
+
+	if found {
+		p.res <- msg
+	}
+}
+
+func isBatch(payload []byte) bool {
+	trimmed := bytes.TrimSpace(payload)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// payloadIDKey returns the normalized ID of a request or response. For a
+// batch it is the sorted set of the IDs of its elements, so a batch response
+// matches its request whatever the order of its elements. Null IDs are left
+// out, as notifications get no response.
+func payloadIDKey(payload []byte) (string, error) {
+	if !isBatch(payload) {
+		var m struct {
+			ID any `json:"id"`
+		}
+		if err := json.Unmarshal(payload, &m); err != nil {
+			return "", err
+		}
+		return idKey(m.ID)
+	}
+
+	var batch []struct {
+		ID any `json:"id"`
+	}
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		return "", err
+	}
+	if len(batch) == 0 {
+		return "", fmt.Errorf("empty batch")
+	}
+	keys := make([]string, 0, len(batch))
+	for _, m := range batch {
+		if m.ID == nil {
+			continue
+		}
+		key, err := idKey(m.ID)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return "[" + strings.Join(keys, ",") + "]", nil
+}
+
+// Subscription is an eth_subscribe subscription opened through a Client
+type Subscription struct {
+	id            string
+	client        *Client
+	conn          *wsConn
+	notifications chan json.RawMessage
+	err           chan error
+	once          sync.Once
+}
+
+func newSubscription(client *Client) *Subscription {
+	return &Subscription{
+		client:        client,
+		notifications: make(chan json.RawMessage, subscriptionBufferSize),
+		err:           make(chan error, 1),
+	}
+}
+
+// ID returns the subscription ID assigned by the node
+func (s *Subscription) ID() string {
+	return s.id
+}
+
+// Notifications returns the channel the notification results are sent to
+func (s *Subscription) Notifications() <-chan json.RawMessage {
+	return s.notifications
+}
+
+// Err returns a channel that receives the error that terminated the
+// subscription. The channel is closed when the subscription ends.
+func (s *Subscription) Err() <-chan error {
+	return s.err
+}
+
+// Unsubscribe cancels the subscription on the node and closes Err
+func (s *Subscription) Unsubscribe(t *testing.T, ctx context.Context) error {
+	s.conn.removeSubscription(s.id)
+	s.terminate(nil)
+	if s.conn.failed() {
+		return nil
+	}
+	return s.client.CallResult(t, ctx, nil, "eth_unsubscribe", s.id)
+}
+
+func (s *Subscription) deliver(result json.RawMessage) bool {
+	select {
+	case s.notifications <- result:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Subscription) terminate(err error) {
+	s.once.Do(func() {
+		if err != nil {
+			s.err <- err
+		}
+		close(s.err)
+	})
+}
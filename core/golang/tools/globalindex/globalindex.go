@@ -0,0 +1,152 @@
+package globalindex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// MainnetFlagBit is the bit set in the global index of mainnet deposits
+	MainnetFlagBit = 64
+	// RollupIndexShift is the offset of the rollup index in the global index
+	RollupIndexShift = 32
+
+	selectorLength = 4
+	wordLength     = 32
+	// globalIndexWord is the position of the global index param in the claim
+	// calldata, after the two 32 levels proofs
+	globalIndexWord = 64
+)
+
+var (
+	// ErrOutOfRange is returned when decoding a global index with bits set
+	// above the mainnet flag
+	ErrOutOfRange = errors.New("global index out of range")
+	// ErrRollupIndexOnMainnet is returned when decoding a mainnet global
+	// index with a non zero rollup index
+	ErrRollupIndexOnMainnet = errors.New("rollup index set on a mainnet global index")
+
+	// ClaimAssetSelector and ClaimMessageSelector are the selectors of the
+	// bridge claim methods taking a global index
+	ClaimAssetSelector   = crypto.Keccak256([]byte("claimAsset(bytes32[32],bytes32[32],uint256,bytes32,bytes32,uint32,address,uint32,address,uint256,bytes)"))[:selectorLength]
+	ClaimMessageSelector = crypto.Keccak256([]byte("claimMessage(bytes32[32],bytes32[32],uint256,bytes32,bytes32,uint32,address,uint32,address,uint256,bytes)"))[:selectorLength]
+
+	maxGlobalIndex = new(big.Int).Lsh(big.NewInt(1), MainnetFlagBit+1)
+)
+
+// GlobalIndex identifies a deposit across all the networks, the 256 bits
+// value is made of the mainnet flag at bit 64, the rollup index at bits
+// 32 to 63 and the local deposit index at bits 0 to 31
+type GlobalIndex struct {
+	Mainnet     bool
+	RollupIndex uint32
+	LocalIndex  uint32
+}
+
+// New returns the global index of the deposit with the deposit count sent
+// from the network, mainnet is network 0 and rollups use their network id
+// minus one as rollup index
+func New(networkID, depositCount uint32) GlobalIndex {
+	if networkID == 0 {
+		return GlobalIndex{Mainnet: true, LocalIndex: depositCount}
+	}
+	return GlobalIndex{RollupIndex: networkID - 1, LocalIndex: depositCount}
+}
+
+// NetworkID returns the network the deposit was sent from
+func (g GlobalIndex) NetworkID() uint32 {
+	if g.Mainnet {
+		return 0
+	}
+	return g.RollupIndex + 1
+}
+
+// Validate checks the rollup index is zero for mainnet deposits
+func (g GlobalIndex) Validate() error {
+	if g.Mainnet && g.RollupIndex != 0 {
+		return fmt.Errorf("%w: %v", ErrRollupIndexOnMainnet, g.RollupIndex)
+	}
+	return nil
+}
+
+// Big returns the 256 bits value of the global index
+func (g GlobalIndex) Big() *big.Int {
+	v := new(big.Int).SetUint64(uint64(g.RollupIndex)<<RollupIndexShift | uint64(g.LocalIndex))
+	if g.Mainnet {
+		v.SetBit(v, MainnetFlagBit, 1)
+	}
+	return v
+}
+
+// String returns the decimal value of the global index
+func (g GlobalIndex) String() string {
+	return g.Big().String()
+}
+
+// Decode splits the value into its components, it fails if the value is
+// negative, has bits set above the mainnet flag or sets a rollup index on a
+// mainnet deposit
+func Decode(v *big.Int) (GlobalIndex, error) {
+	if v == nil || v.Sign() < 0 || v.Cmp(maxGlobalIndex) >= 0 {
+		return GlobalIndex{}, fmt.Errorf("%w: %v", ErrOutOfRange, v)
+	}
+	low := new(big.Int).SetBit(v, MainnetFlagBit, 0).Uint64()
+	g := GlobalIndex{
+		Mainnet:     v.Bit(MainnetFlagBit) == 1,
+		RollupIndex: uint32(low >> RollupIndexShift),
+		LocalIndex:  uint32(low),
+	}
+	return g, g.Validate()
+}
+
+// Parse decodes a decimal or 0x prefixed hex global index
+func Parse(s string) (GlobalIndex, error) {
+	v, ok := new(big.Int), false
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		v, ok = v.SetString(s[2:], 16) //nolint:mnd
+	} else {
+		v, ok = v.SetString(s, 10) //nolint:mnd
+	}
+	if !ok {
+		return GlobalIndex{}, fmt.Errorf("invalid global index %q", s)
+	}
+	return Decode(v)
+}
+
+// MarshalJSON encodes the global index as a decimal string, as the bridge
+// service does
+func (g GlobalIndex) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.String())
+}
+
+// UnmarshalJSON decodes decimal or hex strings and numbers
+func (g *GlobalIndex) UnmarshalJSON(input []byte) error {
+	decoded, err := Parse(strings.Trim(string(input), `"`))
+	if err != nil {
+		return err
+	}
+	*g = decoded
+	return nil
+}
+
+// FromClaimCalldata decodes the global index of a claimAsset or claimMessage
+// call
+func FromClaimCalldata(calldata []byte) (GlobalIndex, error) {
+	if len(calldata) < selectorLength {
+		return GlobalIndex{}, fmt.Errorf("calldata too short: %v bytes", len(calldata))
+	}
+	selector := string(calldata[:selectorLength])
+	if selector != string(ClaimAssetSelector) && selector != string(ClaimMessageSelector) {
+		return GlobalIndex{}, fmt.Errorf("not a claim call: selector 0x%x", calldata[:selectorLength])
+	}
+	start := selectorLength + globalIndexWord*wordLength
+	if len(calldata) < start+wordLength {
+		return GlobalIndex{}, fmt.Errorf("calldata too short: %v bytes", len(calldata))
+	}
+	return Decode(new(big.Int).SetBytes(calldata[start : start+wordLength]))
+}
@@ -0,0 +1,125 @@
+package globalindex
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/agglayer/e2e/core/golang/tools/bridgeservice"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const claimAssetABI = `[{"type":"function","name":"claimAsset","stateMutability":"nonpayable","outputs":[],"inputs":[
+	{"name":"smtProofLocalExitRoot","type":"bytes32[32]"},{"name":"smtProofRollupExitRoot","type":"bytes32[32]"},
+	{"name":"globalIndex","type":"uint256"},{"name":"mainnetExitRoot","type":"bytes32"},{"name":"rollupExitRoot","type":"bytes32"},
+	{"name":"originNetwork","type":"uint32"},{"name":"originTokenAddress","type":"address"},{"name":"destinationNetwork","type":"uint32"},
+	{"name":"destinationAddress","type":"address"},{"name":"amount","type":"uint256"},{"name":"metadata","type":"bytes"}]}]`
+
+// newValid builds a valid global index out of random values
+func newValid(mainnet bool, rollupIndex, localIndex uint32) GlobalIndex {
+	if mainnet {
+		rollupIndex = 0
+	}
+	return GlobalIndex{Mainnet: mainnet, RollupIndex: rollupIndex, LocalIndex: localIndex}
+}
+
+func TestGlobalIndex(t *testing.T) {
+	assert.Equal(t, "18446744073709551621", New(0, 5).String())
+	assert.Equal(t, "4294967301", New(2, 5).String())
+	assert.Equal(t, uint32(2), New(2, 5).NetworkID())
+	assert.Equal(t, uint32(0), New(0, 5).NetworkID())
+
+	g, err := Parse("0x10000000000000005")
+	require.NoError(t, err)
+	assert.Equal(t, New(0, 5), g)
+
+	_, err = Parse("not a number")
+	require.Error(t, err)
+	_, err = Decode(new(big.Int).Lsh(big.NewInt(1), MainnetFlagBit+1))
+	require.ErrorIs(t, err, ErrOutOfRange)
+	_, err = Decode(big.NewInt(-1))
+	require.ErrorIs(t, err, ErrOutOfRange)
+	_, err = Decode(GlobalIndex{RollupIndex: 1}.Big().SetBit(GlobalIndex{RollupIndex: 1}.Big(), MainnetFlagBit, 1))
+	require.ErrorIs(t, err, ErrRollupIndexOnMainnet)
+}
+
+func TestRoundTrip(t *testing.T) {
+	bigRoundTrip := func(mainnet bool, rollupIndex, localIndex uint32) bool {
+		g := newValid(mainnet, rollupIndex, localIndex)
+		decoded, err := Decode(g.Big())
+		return err == nil && decoded == g
+	}
+	require.NoError(t, quick.Check(bigRoundTrip, nil))
+
+	networkRoundTrip := func(networkID, depositCount uint32) bool {
+		g := New(networkID, depositCount)
+		return g.Validate() == nil && g.NetworkID() == networkID && g.LocalIndex == depositCount
+	}
+	require.NoError(t, quick.Check(networkRoundTrip, nil))
+
+	parseRoundTrip := func(mainnet bool, rollupIndex, localIndex uint32) bool {
+		g := newValid(mainnet, rollupIndex, localIndex)
+		decimal, errDecimal := Parse(g.String())
+		hex, errHex := Parse("0x" + g.Big().Text(16))
+		return errDecimal == nil && errHex == nil && decimal == g && hex == g
+	}
+	require.NoError(t, quick.Check(parseRoundTrip, nil))
+
+	outOfRange := func(high uint64, low uint64) bool {
+		v := new(big.Int).Lsh(new(big.Int).SetUint64(high|1), MainnetFlagBit+1)
+		_, err := Decode(v.Or(v, new(big.Int).SetUint64(low)))
+		return err != nil
+	}
+	require.NoError(t, quick.Check(outOfRange, nil))
+}
+
+func TestBridgeServiceJSON(t *testing.T) {
+	check := func(mainnet bool, rollupIndex, localIndex uint32) bool {
+		g := newValid(mainnet, rollupIndex, localIndex)
+		encoded, err := json.Marshal(bridgeservice.Claim{GlobalIndex: bridgeservice.NewBigIntString(g.Big())})
+		if err != nil {
+			return false
+		}
+
+		var fields struct {
+			GlobalIndex GlobalIndex `json:"global_index"`
+		}
+		if json.Unmarshal(encoded, &fields) != nil || fields.GlobalIndex != g {
+			return false
+		}
+
+		encoded, err = json.Marshal(fields)
+		if err != nil {
+			return false
+		}
+		var claim bridgeservice.Claim
+		return json.Unmarshal(encoded, &claim) == nil && claim.GlobalIndex.Cmp(g.Big()) == 0
+	}
+	require.NoError(t, quick.Check(check, nil))
+}
+
+func TestClaimCalldata(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(claimAssetABI))
+	require.NoError(t, err)
+	assert.Equal(t, parsed.Methods["claimAsset"].ID, ClaimAssetSelector)
+
+	check := func(mainnet bool, rollupIndex, localIndex uint32, metadata []byte) bool {
+		g := newValid(mainnet, rollupIndex, localIndex)
+		calldata, err := parsed.Pack("claimAsset", [32][32]byte{}, [32][32]byte{}, g.Big(), [32]byte{}, [32]byte{},
+			uint32(0), common.Address{}, uint32(1), common.Address{}, big.NewInt(1), metadata)
+		if err != nil {
+			return false
+		}
+		decoded, err := FromClaimCalldata(calldata)
+		return err == nil && decoded == g
+	}
+	require.NoError(t, quick.Check(check, nil))
+
+	_, err = FromClaimCalldata([]byte{0x1, 0x2, 0x3, 0x4})
+	require.Error(t, err)
+}
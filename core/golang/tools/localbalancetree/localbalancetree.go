@@ -0,0 +1,237 @@
+package localbalancetree
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Depth is the number of levels of the tree, a key is made of the 32 bits of
+// the origin network followed by the 160 bits of the origin token address
+const Depth = 192
+
+var (
+	// ErrBalanceUnderflow is returned when a bridge exit spends more than the
+	// balance of the token
+	ErrBalanceUnderflow = errors.New("balance underflow")
+	// ErrBalanceOverflow is returned when the balance of a token doesn't fit
+	// in 256 bits
+	ErrBalanceOverflow = errors.New("balance overflow")
+)
+
+// EmptyHashes are the roots of the empty subtrees of each height,
+// EmptyHashes[0] is the empty leaf and EmptyHashes[Depth] the root of the
+// empty tree
+var EmptyHashes = func() [Depth + 1]common.Hash {
+	var hashes [Depth + 1]common.Hash
+	for h := 1; h <= Depth; h++ {
+		hashes[h] = crypto.Keccak256Hash(hashes[h-1].Bytes(), hashes[h-1].Bytes())
+	}
+	return hashes
+}()
+
+// BalanceError is returned when applying an exit makes a token balance go
+// out of range
+type BalanceError struct {
+	Token   agglayer.TokenInfo
+	Balance *big.Int
+	Amount  *big.Int
+	// Err is ErrBalanceUnderflow or ErrBalanceOverflow
+	Err error
+}
+
+// Error returns the error message.
+func (e *BalanceError) Error() string {
+	return fmt.Sprintf("%v for token %v of network %v: balance %v, amount %v",
+		e.Err, e.Token.OriginTokenAddress.String(), e.Token.OriginNetwork, e.Balance, e.Amount)
+}
+
+// Unwrap returns ErrBalanceUnderflow or ErrBalanceOverflow
+func (e *BalanceError) Unwrap() error {
+	return e.Err
+}
+
+// Tree is an in memory model of the local balance tree of a network, as
+// tracked by the pessimistic proof. It holds the balance of each token of the
+// other networks, the tokens of the network itself are not tracked.
+type Tree struct {
+	networkID agglayer.NetworkID
+	balances  map[agglayer.TokenInfo]*big.Int
+}
+
+// New creates the empty local balance tree of the network
+func New(networkID agglayer.NetworkID) *Tree {
+	return &Tree{networkID: networkID, balances: map[agglayer.TokenInfo]*big.Int{}}
+}
+
+// NetworkID returns the network the tree belongs to
+func (t *Tree) NetworkID() agglayer.NetworkID {
+	return t.networkID
+}
+
+// Clone returns a copy of the tree
+func (t *Tree) Clone() *Tree {
+	clone := New(t.networkID)
+	for token, balance := range t.balances {
+		clone.balances[token] = new(big.Int).Set(balance)
+	}
+	return clone
+}
+
+// Balance returns the balance of the token, zero if it isn't in the tree
+func (t *Tree) Balance(token agglayer.TokenInfo) *big.Int {
+	if balance, found := t.balances[token]; found {
+		return new(big.Int).Set(balance)
+	}
+	return new(big.Int)
+}
+
+// SetBalance sets the balance of the token, e.g. to start from the balances
+// of a settled certificate
+func (t *Tree) SetBalance(token agglayer.TokenInfo, balance *big.Int) error {
+	if balance.Sign() < 0 {
+		return &BalanceError{Token: token, Balance: balance, Amount: new(big.Int), Err: ErrBalanceUnderflow}
+	}
+	if balance.Cmp(math.MaxBig256) > 0 {
+		return &BalanceError{Token: token, Balance: balance, Amount: new(big.Int), Err: ErrBalanceOverflow}
+	}
+	t.balances[token] = new(big.Int).Set(balance)
+	return nil
+}
+
+// Tokens returns the tokens in the tree, sorted by key
+func (t *Tree) Tokens() []agglayer.TokenInfo {
+	tokens := make([]agglayer.TokenInfo, 0, len(t.balances))
+	for token := range t.balances {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].OriginNetwork != tokens[j].OriginNetwork {
+			return tokens[i].OriginNetwork < tokens[j].OriginNetwork
+		}
+		return tokens[i].OriginTokenAddress.Cmp(tokens[j].OriginTokenAddress) < 0
+	})
+	return tokens
+}
+
+// Apply credits the imported bridge exits then debits the bridge exits of
+// the certificate, as the pessimistic proof does. The amount of the message
+// exits is taken from L1 ETH, whatever their token. The tree is left
+// unchanged if a balance gets out of range.
+func (t *Tree) Apply(certificate *agglayer.Certificate) error {
+	next := t.Clone()
+	for _, imported := range certificate.ImportedBridgeExits {
+		next.credit(exitToken(imported.BridgeExit), imported.BridgeExit.Amount.Int)
+	}
+	for token, balance := range next.balances {
+		if balance.Cmp(math.MaxBig256) > 0 {
+			prev := t.Balance(token)
+			return &BalanceError{Token: token, Balance: prev, Amount: new(big.Int).Sub(balance, prev), Err: ErrBalanceOverflow}
+		}
+	}
+	for _, exit := range certificate.BridgeExits {
+		if err := next.debit(exitToken(exit), exit.Amount.Int); err != nil {
+			return err
+		}
+	}
+	t.balances = next.balances
+	return nil
+}
+
+// Check reports whether the certificate can be applied, without updating the
+// tree
+func (t *Tree) Check(certificate *agglayer.Certificate) error {
+	return t.Clone().Apply(certificate)
+}
+
+// exitToken returns the token whose balance the exit moves, L1 ETH for the
+// messages
+func exitToken(exit agglayer.BridgeExit) agglayer.TokenInfo {
+	if exit.LeafType == agglayer.LeafTypeMessage {
+		return agglayer.TokenInfo{OriginNetwork: 0, OriginTokenAddress: common.Address{}}
+	}
+	return exit.TokenInfo
+}
+
+func (t *Tree) credit(token agglayer.TokenInfo, amount *big.Int) {
+	if token.OriginNetwork == t.networkID || amount == nil {
+		return
+	}
+	balance, found := t.balances[token]
+	if !found {
+		balance = new(big.Int)
+		t.balances[token] = balance
+	}
+	balance.Add(balance, amount)
+}
+
+func (t *Tree) debit(token agglayer.TokenInfo, amount *big.Int) error {
+	if token.OriginNetwork == t.networkID || amount == nil {
+		return nil
+	}
+	balance := t.Balance(token)
+	if balance.Cmp(amount) < 0 {
+		return &BalanceError{Token: token, Balance: balance, Amount: new(big.Int).Set(amount), Err: ErrBalanceUnderflow}
+	}
+	t.balances[token] = balance.Sub(balance, amount)
+	return nil
+}
+
+// Key returns the bits of the key of the token, from the root to the leaf:
+// the origin network bits then the origin token address bits, each byte
+// being read from its least significant bit
+func Key(token agglayer.TokenInfo) [Depth]bool {
+	var bits [Depth]bool
+	for i := 0; i < 32; i++ {
+		bits[i] = (uint32(token.OriginNetwork)>>i)&1 == 1
+	}
+	for i := 32; i < Depth; i++ {
+		bits[i] = (token.OriginTokenAddress[(i-32)/8]>>(i%8))&1 == 1
+	}
+	return bits
+}
+
+// LeafValue returns the leaf of a balance, its 32 bytes big endian value
+func LeafValue(balance *big.Int) common.Hash {
+	return common.BigToHash(balance)
+}
+
+// Root returns the root of the tree, the tokens with a zero balance are
+// kept as they are in the tree of the pessimistic proof
+func (t *Tree) Root() common.Hash {
+	leaves := make([]leaf, 0, len(t.balances))
+	for token, balance := range t.balances {
+		leaves = append(leaves, leaf{key: Key(token), value: LeafValue(balance)})
+	}
+	return root(leaves, 0)
+}
+
+type leaf struct {
+	key   [Depth]bool
+	value common.Hash
+}
+
+// root returns the root of the subtree at the depth holding the leaves
+func root(leaves []leaf, depth int) common.Hash {
+	if len(leaves) == 0 {
+		return EmptyHashes[Depth-depth]
+	}
+	if depth == Depth {
+		return leaves[0].value
+	}
+	var left, right []leaf
+	for _, l := range leaves {
+		if l.key[depth] {
+			right = append(right, l)
+		} else {
+			left = append(left, l)
+		}
+	}
+	return crypto.Keccak256Hash(root(left, depth+1).Bytes(), root(right, depth+1).Bytes())
+}
@@ -0,0 +1,107 @@
+package localbalancetree
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/agglayer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func transfer(token agglayer.TokenInfo, amount int64) agglayer.BridgeExit {
+	return agglayer.BridgeExit{
+		LeafType:  agglayer.LeafTypeTransfer,
+		TokenInfo: token,
+		Amount:    agglayer.NewU256(big.NewInt(amount)),
+	}
+}
+
+func TestRoot(t *testing.T) {
+	tree := New(1)
+	assert.Equal(t, EmptyHashes[Depth], tree.Root())
+
+	// a single leaf is hashed with the empty subtrees along its key
+	eth := agglayer.TokenInfo{}
+	require.NoError(t, tree.SetBalance(eth, big.NewInt(10))) //nolint:mnd
+	expected := LeafValue(big.NewInt(10))                    //nolint:mnd
+	for h := 0; h < Depth; h++ {
+		expected = crypto.Keccak256Hash(expected.Bytes(), EmptyHashes[h].Bytes())
+	}
+	assert.Equal(t, expected, tree.Root())
+
+	// the first bit of the key is the lowest bit of the origin network
+	token := agglayer.TokenInfo{OriginNetwork: 1}
+	key := Key(token)
+	assert.True(t, key[0])
+	assert.Equal(t, []bool{true, false}, []bool{Key(agglayer.TokenInfo{OriginTokenAddress: common.Address{1}})[32], key[1]})
+
+	require.NoError(t, tree.SetBalance(token, big.NewInt(3)))          //nolint:mnd
+	left, right := LeafValue(big.NewInt(10)), LeafValue(big.NewInt(3)) //nolint:mnd
+	for h := 0; h < Depth-1; h++ {
+		left = crypto.Keccak256Hash(left.Bytes(), EmptyHashes[h].Bytes())
+		right = crypto.Keccak256Hash(right.Bytes(), EmptyHashes[h].Bytes())
+	}
+	assert.Equal(t, crypto.Keccak256Hash(left.Bytes(), right.Bytes()), tree.Root())
+}
+
+func TestApply(t *testing.T) {
+	eth := agglayer.TokenInfo{}
+	local := agglayer.TokenInfo{OriginNetwork: 1, OriginTokenAddress: common.HexToAddress("0x10ca1")}
+	tree := New(1)
+
+	certificate := &agglayer.Certificate{
+		ImportedBridgeExits: []agglayer.ImportedBridgeExit{{BridgeExit: transfer(eth, 10)}}, //nolint:mnd
+		BridgeExits:         []agglayer.BridgeExit{transfer(eth, 4), transfer(local, 100)},  //nolint:mnd
+	}
+	require.NoError(t, tree.Apply(certificate))
+	assert.Equal(t, big.NewInt(6), tree.Balance(eth)) //nolint:mnd
+	// the tokens of the network itself are not tracked
+	assert.Equal(t, []agglayer.TokenInfo{eth}, tree.Tokens())
+
+	root := tree.Root()
+	underflow := &agglayer.Certificate{BridgeExits: []agglayer.BridgeExit{transfer(eth, 2), transfer(eth, 5)}} //nolint:mnd
+	err := tree.Check(underflow)
+	require.ErrorIs(t, err, ErrBalanceUnderflow)
+	var balanceErr *BalanceError
+	require.ErrorAs(t, err, &balanceErr)
+	assert.Equal(t, eth, balanceErr.Token)
+	assert.Equal(t, big.NewInt(4), balanceErr.Balance) //nolint:mnd
+	require.ErrorIs(t, tree.Apply(underflow), ErrBalanceUnderflow)
+	assert.Equal(t, root, tree.Root())
+
+	overflow := &agglayer.Certificate{ImportedBridgeExits: []agglayer.ImportedBridgeExit{
+		{BridgeExit: agglayer.BridgeExit{TokenInfo: eth, Amount: agglayer.NewU256(math.MaxBig256)}},
+	}}
+	require.ErrorIs(t, tree.Apply(overflow), ErrBalanceOverflow)
+	assert.Equal(t, root, tree.Root())
+
+	// spending the imported amount in the same certificate is accepted
+	overflow.BridgeExits = []agglayer.BridgeExit{{TokenInfo: eth, Amount: agglayer.NewU256(math.MaxBig256)}}
+	require.ErrorIs(t, tree.Check(overflow), ErrBalanceOverflow)
+	require.NoError(t, tree.SetBalance(eth, new(big.Int)))
+	require.NoError(t, tree.Apply(overflow))
+	assert.Equal(t, new(big.Int), tree.Balance(eth))
+
+	require.ErrorIs(t, tree.SetBalance(eth, new(big.Int).Add(math.MaxBig256, big.NewInt(1))), ErrBalanceOverflow)
+
+	// the messages move L1 ETH whatever their token
+	token := agglayer.TokenInfo{OriginNetwork: 2, OriginTokenAddress: common.HexToAddress("0x70c3")} //nolint:mnd
+	message := func(amount int64) agglayer.BridgeExit {
+		exit := transfer(token, amount)
+		exit.LeafType = agglayer.LeafTypeMessage
+		return exit
+	}
+	messages := &agglayer.Certificate{
+		ImportedBridgeExits: []agglayer.ImportedBridgeExit{{BridgeExit: message(8)}}, //nolint:mnd
+		BridgeExits:         []agglayer.BridgeExit{message(3)},                       //nolint:mnd
+	}
+	require.NoError(t, tree.Apply(messages))
+	assert.Equal(t, big.NewInt(5), tree.Balance(eth)) //nolint:mnd
+	assert.Equal(t, new(big.Int), tree.Balance(token))
+	assert.Equal(t, []agglayer.TokenInfo{eth}, tree.Tokens())
+	require.ErrorIs(t, tree.Check(&agglayer.Certificate{BridgeExits: []agglayer.BridgeExit{message(6)}}), ErrBalanceUnderflow) //nolint:mnd
+}
@@ -0,0 +1,224 @@
+package merkletree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Height is the number of levels of the local exit trees, the rollup exit
+// tree and the L1 info tree
+const Height = 32
+
+// ZeroHashes are the roots of the empty subtrees of each height, ZeroHashes[0]
+// is the empty leaf and ZeroHashes[Height] the root of the empty tree
+var ZeroHashes = func() [Height + 1]common.Hash {
+	var hashes [Height + 1]common.Hash
+	for h := 1; h <= Height; h++ {
+		hashes[h] = crypto.Keccak256Hash(hashes[h-1].Bytes(), hashes[h-1].Bytes())
+	}
+	return hashes
+}()
+
+// Proof is the list of siblings of a leaf, from the leaf level to the root
+type Proof [Height]common.Hash
+
+// Tree is an append only keccak sparse merkle tree, as the one of the
+// DepositContract of the bridge. The root is kept up to date with the
+// frontier of the tree like the contract does, the leaves are also stored
+// to build proofs and the roots of any previous size.
+type Tree struct {
+	leaves []common.Hash
+	branch [Height]common.Hash
+}
+
+// NewTree creates a tree with the given leaves
+func NewTree(leaves ...common.Hash) *Tree {
+	t := &Tree{}
+	for _, leaf := range leaves {
+		t.Add(leaf)
+	}
+	return t
+}
+
+// Add appends the leaf and returns its index
+func (t *Tree) Add(leaf common.Hash) uint32 {
+	index := uint32(len(t.leaves))
+	t.leaves = append(t.leaves, leaf)
+
+	node := leaf
+	size := len(t.leaves)
+	for h := 0; h < Height; h++ {
+		if (size>>h)&1 == 1 {
+			t.branch[h] = node
+			return index
+		}
+		node = crypto.Keccak256Hash(t.branch[h].Bytes(), node.Bytes())
+	}
+	return index
+}
+
+// Set replaces the leaf at index, filling the gap with empty leaves if the
+// index is beyond the current size, as done for the rollup exit tree where
+// each rollup has a fixed index
+func (t *Tree) Set(index uint32, leaf common.Hash) {
+	leaves := t.leaves
+	for uint32(len(leaves)) <= index {
+		leaves = append(leaves, ZeroHashes[0])
+	}
+	leaves[index] = leaf
+
+	*t = Tree{}
+	for _, l := range leaves {
+		t.Add(l)
+	}
+}
+
+// Count returns the number of leaves
+func (t *Tree) Count() uint32 {
+	return uint32(len(t.leaves))
+}
+
+// Leaf returns the leaf at index
+func (t *Tree) Leaf(index uint32) (common.Hash, error) {
+	if index >= t.Count() {
+		return common.Hash{}, fmt.Errorf("leaf %v not found, the tree has %v leaves", index, t.Count())
+	}
+	return t.leaves[index], nil
+}
+
+// Root returns the root of the tree
+func (t *Tree) Root() common.Hash {
+	var node common.Hash
+	size := len(t.leaves)
+	for h := 0; h < Height; h++ {
+		if (size>>h)&1 == 1 {
+			node = crypto.Keccak256Hash(t.branch[h].Bytes(), node.Bytes())
+		} else {
+			node = crypto.Keccak256Hash(node.Bytes(), ZeroHashes[h].Bytes())
+		}
+	}
+	return node
+}
+
+// RootAt returns the root the tree had when it had count leaves
+func (t *Tree) RootAt(count uint32) (common.Hash, error) {
+	if count > t.Count() {
+		return common.Hash{}, fmt.Errorf("the tree has %v leaves, can't get the root with %v", t.Count(), count)
+	}
+	if count == 0 {
+		return ZeroHashes[Height], nil
+	}
+	return t.levels(count)[Height][0], nil
+}
+
+// Proof returns the proof of the leaf at index against the current root
+func (t *Tree) Proof(index uint32) (Proof, error) {
+	return t.ProofAt(index, t.Count())
+}
+
+// ProofAt returns the proof of the leaf at index against the root the tree
+// had when it had count leaves
+func (t *Tree) ProofAt(index, count uint32) (Proof, error) {
+	if count > t.Count() || index >= count {
+		return Proof{}, fmt.Errorf("can't prove leaf %v with %v of the %v leaves of the tree", index, count, t.Count())
+	}
+
+	var proof Proof
+	levels := t.levels(count)
+	position := int(index)
+	for h := 0; h < Height; h++ {
+		sibling := position ^ 1
+		if sibling < len(levels[h]) {
+			proof[h] = levels[h][sibling]
+		} else {
+			proof[h] = ZeroHashes[h]
+		}
+		position >>= 1
+	}
+	return proof, nil
+}
+
+// levels returns the non empty nodes of every level of the tree built with
+// the first count leaves
+func (t *Tree) levels(count uint32) [][]common.Hash {
+	levels := make([][]common.Hash, Height+1)
+	levels[0] = t.leaves[:count]
+	for h := 0; h < Height; h++ {
+		current := levels[h]
+		next := make([]common.Hash, (len(current)+1)/2) //nolint:mnd
+		for i := range next {
+			right := ZeroHashes[h]
+			if 2*i+1 < len(current) {
+				right = current[2*i+1]
+			}
+			next[i] = crypto.Keccak256Hash(current[2*i].Bytes(), right.Bytes())
+		}
+		levels[h+1] = next
+	}
+	return levels
+}
+
+// CalculateRoot returns the root resulting from the leaf at index and its
+// proof, as calculateRoot of the bridge contract
+func CalculateRoot(leaf common.Hash, proof Proof, index uint32) common.Hash {
+	node := leaf
+	for h := 0; h < Height; h++ {
+		if (index>>h)&1 == 1 {
+			node = crypto.Keccak256Hash(proof[h].Bytes(), node.Bytes())
+		} else {
+			node = crypto.Keccak256Hash(node.Bytes(), proof[h].Bytes())
+		}
+	}
+	return node
+}
+
+// VerifyMerkleProof reports whether the proof of the leaf at index leads to
+// the root, as verifyMerkleProof of the bridge contract
+func VerifyMerkleProof(leaf common.Hash, proof Proof, index uint32, root common.Hash) bool {
+	return CalculateRoot(leaf, proof, index) == root
+}
+
+// ExitLeafHash returns the hash of a local exit tree leaf, as getLeafValue
+// of the bridge contract
+func ExitLeafHash(leafType uint8, originNetwork uint32, originAddress common.Address, destinationNetwork uint32,
+	destinationAddress common.Address, amount *big.Int, metadataHash common.Hash) common.Hash {
+	var originNetworkBytes, destinationNetworkBytes [4]byte
+	binary.BigEndian.PutUint32(originNetworkBytes[:], originNetwork)
+	binary.BigEndian.PutUint32(destinationNetworkBytes[:], destinationNetwork)
+	var amountBytes [32]byte
+	if amount != nil {
+		amount.FillBytes(amountBytes[:])
+	}
+
+	return crypto.Keccak256Hash(
+		[]byte{leafType},
+		originNetworkBytes[:],
+		originAddress.Bytes(),
+		destinationNetworkBytes[:],
+		destinationAddress.Bytes(),
+		amountBytes[:],
+		metadataHash.Bytes(),
+	)
+}
+
+// GlobalExitRoot returns the global exit root of the exit roots
+func GlobalExitRoot(mainnetExitRoot, rollupExitRoot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(mainnetExitRoot.Bytes(), rollupExitRoot.Bytes())
+}
+
+// L1InfoTreeLeafHash returns the hash of an L1 info tree leaf
+func L1InfoTreeLeafHash(globalExitRoot, previousBlockHash common.Hash, timestamp uint64) common.Hash {
+	var timestampBytes [8]byte
+	binary.BigEndian.PutUint64(timestampBytes[:], timestamp)
+	return crypto.Keccak256Hash(globalExitRoot.Bytes(), previousBlockHash.Bytes(), timestampBytes[:])
+}
+
+// RollupExitRoot returns the root of the rollup exit tree made of the local
+// exit roots of the rollups, indexed by rollup id minus one
+func RollupExitRoot(localExitRoots ...common.Hash) common.Hash {
+	return NewTree(localExitRoots...).Root()
+}
@@ -0,0 +1,92 @@
+package merkletree
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLeaves(n int) []common.Hash {
+	leaves := make([]common.Hash, n)
+	for i := range leaves {
+		leaves[i] = crypto.Keccak256Hash(big.NewInt(int64(i)).Bytes())
+	}
+	return leaves
+}
+
+func TestEmptyTree(t *testing.T) {
+	tree := NewTree()
+	// root of the empty deposit contract
+	assert.Equal(t, common.HexToHash("0x27ae5ba08d7291c96c8cbddcc148bf48a6d68c7974b94356f53754ef6171d757"), tree.Root())
+	assert.Equal(t, ZeroHashes[Height], tree.Root())
+
+	_, err := tree.Proof(0)
+	require.Error(t, err)
+}
+
+func TestTree(t *testing.T) {
+	leaves := testLeaves(17) //nolint:mnd
+	tree := NewTree()
+	roots := []common.Hash{tree.Root()}
+	for i, leaf := range leaves {
+		assert.Equal(t, uint32(i), tree.Add(leaf))
+		roots = append(roots, tree.Root())
+	}
+
+	for count := range roots {
+		root, err := tree.RootAt(uint32(count))
+		require.NoError(t, err)
+		assert.Equalf(t, roots[count], root, "root with %v leaves", count)
+
+		for index := 0; index < count; index++ {
+			proof, err := tree.ProofAt(uint32(index), uint32(count))
+			require.NoError(t, err)
+			assert.Truef(t, VerifyMerkleProof(leaves[index], proof, uint32(index), roots[count]), "leaf %v with %v leaves", index, count)
+			assert.False(t, VerifyMerkleProof(leaves[index], proof, uint32(index)^1, roots[count]))
+		}
+	}
+
+	_, err := tree.ProofAt(3, 3) //nolint:mnd
+	require.Error(t, err)
+	_, err = tree.RootAt(18) //nolint:mnd
+	require.Error(t, err)
+}
+
+func TestSet(t *testing.T) {
+	leaves := testLeaves(3) //nolint:mnd
+
+	tree := NewTree()
+	tree.Set(2, leaves[2]) //nolint:mnd
+	assert.Equal(t, uint32(3), tree.Count())
+	assert.Equal(t, NewTree(common.Hash{}, common.Hash{}, leaves[2]).Root(), tree.Root())
+
+	tree.Set(0, leaves[0])
+	assert.Equal(t, NewTree(leaves[0], common.Hash{}, leaves[2]).Root(), tree.Root())
+	assert.Equal(t, RollupExitRoot(leaves[0], common.Hash{}, leaves[2]), tree.Root())
+
+	proof, err := tree.Proof(2) //nolint:mnd
+	require.NoError(t, err)
+	assert.True(t, VerifyMerkleProof(leaves[2], proof, 2, tree.Root())) //nolint:mnd
+}
+
+func TestLeafHashes(t *testing.T) {
+	// abi.encodePacked of the leaf fields, as getLeafValue does
+	metadataHash := crypto.Keccak256Hash(nil)
+	leaf := ExitLeafHash(0, 0, common.Address{}, 1, common.HexToAddress("0xc949254d682d8c9ad5682521675b8f43b102aec4"),
+		big.NewInt(1000), metadataHash)
+	expected := crypto.Keccak256Hash(common.FromHex(
+		"00"+"00000000"+"0000000000000000000000000000000000000000"+"00000001"+
+			"c949254d682d8c9ad5682521675b8f43b102aec4"+
+			"00000000000000000000000000000000000000000000000000000000000003e8"), metadataHash.Bytes())
+	assert.Equal(t, expected, leaf)
+
+	ger := GlobalExitRoot(common.HexToHash("0x1"), common.HexToHash("0x2"))
+	assert.Equal(t, crypto.Keccak256Hash(common.HexToHash("0x1").Bytes(), common.HexToHash("0x2").Bytes()), ger)
+	assert.Equal(t,
+		crypto.Keccak256Hash(ger.Bytes(), common.HexToHash("0x3").Bytes(), common.FromHex("0000000000000004")),
+		L1InfoTreeLeafHash(ger, common.HexToHash("0x3"), 4)) //nolint:mnd
+}
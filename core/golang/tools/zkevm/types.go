@@ -0,0 +1,182 @@
+package zkevm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/agglayer/e2e/core/golang/tools/hex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Batch is a batch as returned by zkevm_getBatchByNumber
+type Batch struct {
+	Number              hexutil.Uint64    `json:"number"`
+	ForcedBatchNumber   *hexutil.Uint64   `json:"forcedBatchNumber,omitempty"`
+	Coinbase            common.Address    `json:"coinbase"`
+	StateRoot           common.Hash       `json:"stateRoot"`
+	GlobalExitRoot      common.Hash       `json:"globalExitRoot"`
+	MainnetExitRoot     common.Hash       `json:"mainnetExitRoot"`
+	RollupExitRoot      common.Hash       `json:"rollupExitRoot"`
+	LocalExitRoot       common.Hash       `json:"localExitRoot"`
+	AccInputHash        common.Hash       `json:"accInputHash"`
+	Timestamp           hexutil.Uint64    `json:"timestamp"`
+	SendSequencesTxHash *common.Hash      `json:"sendSequencesTxHash"`
+	VerifyBatchTxHash   *common.Hash      `json:"verifyBatchTxHash"`
+	Closed              bool              `json:"closed"`
+	Blocks              []json.RawMessage `json:"blocks"`
+	Transactions        []json.RawMessage `json:"transactions"`
+	BatchL2Data         hexutil.Bytes     `json:"batchL2Data"`
+}
+
+// IsVirtualized reports whether the batch was sequenced on L1
+func (b *Batch) IsVirtualized() bool {
+	return b.SendSequencesTxHash != nil && *b.SendSequencesTxHash != (common.Hash{})
+}
+
+// IsVerified reports whether the batch was verified on L1
+func (b *Batch) IsVerified() bool {
+	return b.VerifyBatchTxHash != nil && *b.VerifyBatchTxHash != (common.Hash{})
+}
+
+// BlockHashes returns the hashes of the blocks in the batch. It fails if the
+// blocks were returned as objects.
+func (b *Batch) BlockHashes() ([]common.Hash, error) {
+	return decodeHashes(b.Blocks)
+}
+
+// TransactionHashes returns the hashes of the transactions in the batch. It
+// fails if the transactions were returned as objects.
+func (b *Batch) TransactionHashes() ([]common.Hash, error) {
+	return decodeHashes(b.Transactions)
+}
+
+func decodeHashes(items []json.RawMessage) ([]common.Hash, error) {
+	hashes := make([]common.Hash, len(items))
+	for i, item := range items {
+		if err := json.Unmarshal(item, &hashes[i]); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// ExitRoots are the exit roots that compose a global exit root, as returned
+// by zkevm_getExitRootsByGER
+type ExitRoots struct {
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+	Timestamp       hexutil.Uint64 `json:"timestamp"`
+	MainnetExitRoot common.Hash    `json:"mainnetExitRoot"`
+	RollupExitRoot  common.Hash    `json:"rollupExitRoot"`
+}
+
+// TxArgs are the tx fields sent to zkevm_estimateCounters
+type TxArgs struct {
+	From     *common.Address `json:"from,omitempty"`
+	To       *common.Address `json:"to,omitempty"`
+	Gas      *hexutil.Uint64 `json:"gas,omitempty"`
+	GasPrice *hexutil.Big    `json:"gasPrice,omitempty"`
+	Value    *hexutil.Big    `json:"value,omitempty"`
+	Nonce    *hexutil.Uint64 `json:"nonce,omitempty"`
+	Input    hexutil.Bytes   `json:"input,omitempty"`
+}
+
+// TxToArgs converts a tx into the args to estimate its counters
+func TxToArgs(tx *types.Transaction, from common.Address) TxArgs {
+	gas := hexutil.Uint64(tx.Gas())
+	nonce := hexutil.Uint64(tx.Nonce())
+	return TxArgs{
+		From:     &from,
+		To:       tx.To(),
+		Gas:      &gas,
+		GasPrice: (*hexutil.Big)(tx.GasPrice()),
+		Value:    (*hexutil.Big)(tx.Value()),
+		Nonce:    &nonce,
+		Input:    tx.Data(),
+	}
+}
+
+// Quantity is a number that nodes encode either as a JSON number or as a
+// hex or decimal string
+type Quantity uint64
+
+// UnmarshalJSON decodes numbers, hex strings and decimal strings
+func (q *Quantity) UnmarshalJSON(input []byte) error {
+	var n uint64
+	if err := json.Unmarshal(input, &n); err == nil {
+		*q = Quantity(n)
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(input, &str); err != nil {
+		return fmt.Errorf("invalid quantity %v: %w", string(input), err)
+	}
+	if strings.HasPrefix(str, "0x") {
+		*q = Quantity(hex.DecodeUint64(str))
+		return nil
+	}
+	n, err := strconv.ParseUint(str, 10, 64) //nolint:mnd
+	if err != nil {
+		return fmt.Errorf("invalid quantity %v: %w", str, err)
+	}
+	*q = Quantity(n)
+	return nil
+}
+
+// ZKCounters are the zk counters used by a tx or the limits of a batch
+type ZKCounters struct {
+	Gas              Quantity `json:"gas"`
+	KeccakHashes     Quantity `json:"keccakHashes"`
+	PoseidonHashes   Quantity `json:"poseidonHashes"`
+	PoseidonPaddings Quantity `json:"poseidonPaddings"`
+	MemAligns        Quantity `json:"memAligns"`
+	Arithmetics      Quantity `json:"arithmetics"`
+	Binaries         Quantity `json:"binaries"`
+	Steps            Quantity `json:"steps"`
+	SHA256Hashes     Quantity `json:"SHA256hashes"`
+}
+
+// Get returns the counter with the given name, matched case insensitively
+// against the JSON names, e.g. keccakHashes or poseidonhashes
+func (c ZKCounters) Get(name string) (uint64, bool) {
+	counters := map[string]Quantity{
+		"gas":              c.Gas,
+		"keccakhashes":     c.KeccakHashes,
+		"poseidonhashes":   c.PoseidonHashes,
+		"poseidonpaddings": c.PoseidonPaddings,
+		"memaligns":        c.MemAligns,
+		"arithmetics":      c.Arithmetics,
+		"binaries":         c.Binaries,
+		"steps":            c.Steps,
+		"sha256hashes":     c.SHA256Hashes,
+	}
+	v, found := counters[strings.ToLower(name)]
+	return uint64(v), found
+}
+
+// RevertInfo describes why the tx reverted during the counters estimation
+type RevertInfo struct {
+	Message string        `json:"message"`
+	Data    hexutil.Bytes `json:"data,omitempty"`
+}
+
+// CountersResponse is the result of zkevm_estimateCounters
+type CountersResponse struct {
+	CountersUsed   ZKCounters  `json:"countersUsed"`
+	CountersLimits ZKCounters  `json:"countersLimits"`
+	RevertInfo     *RevertInfo `json:"revertInfo,omitempty"`
+	OOCError       string      `json:"oocError,omitempty"`
+}
+
+// FailureReason returns the revert message or, if none, the out of counters
+// error
+func (r *CountersResponse) FailureReason() string {
+	if r.RevertInfo != nil && r.RevertInfo.Message != "" {
+		return r.RevertInfo.Message
+	}
+	return r.OOCError
+}
@@ -0,0 +1,121 @@
+package zkevm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/agglayer/e2e/core/golang/tools/hex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Client is a typed client for the zkevm JSON RPC namespace exposed by
+// cdk-erigon
+type Client struct {
+	rpc *engine.Client
+}
+
+// NewClient creates a zkevm client for the provided URL
+func NewClient(url string, opts ...engine.ClientOption) *Client {
+	return &Client{rpc: engine.NewClient(url, opts...)}
+}
+
+// NewClientFrom creates a zkevm client on top of an existing JSON RPC client
+func NewClientFrom(rpc *engine.Client) *Client {
+	return &Client{rpc: rpc}
+}
+
+// RPC returns the underlying JSON RPC client
+func (c *Client) RPC() *engine.Client {
+	return c.rpc
+}
+
+func (c *Client) callUint64(t *testing.T, ctx context.Context, method string, params ...any) (uint64, error) {
+	var result hexutil.Uint64
+	err := c.rpc.CallResult(t, ctx, &result, method, params...)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(result), nil
+}
+
+// ForkID returns the current fork id
+func (c *Client) ForkID(t *testing.T, ctx context.Context) (uint64, error) {
+	return c.callUint64(t, ctx, "zkevm_getForkId")
+}
+
+// ForkIDByBatchNumber returns the fork id used to process the batch
+func (c *Client) ForkIDByBatchNumber(t *testing.T, ctx context.Context, batchNumber uint64) (uint64, error) {
+	return c.callUint64(t, ctx, "zkevm_getForkIdByBatchNumber", hex.EncodeUint64(batchNumber))
+}
+
+// BatchNumber returns the latest batch number
+func (c *Client) BatchNumber(t *testing.T, ctx context.Context) (uint64, error) {
+	return c.callUint64(t, ctx, "zkevm_batchNumber")
+}
+
+// VirtualBatchNumber returns the latest batch sequenced on L1
+func (c *Client) VirtualBatchNumber(t *testing.T, ctx context.Context) (uint64, error) {
+	return c.callUint64(t, ctx, "zkevm_virtualBatchNumber")
+}
+
+// VerifiedBatchNumber returns the latest batch verified on L1
+func (c *Client) VerifiedBatchNumber(t *testing.T, ctx context.Context) (uint64, error) {
+	return c.callUint64(t, ctx, "zkevm_verifiedBatchNumber")
+}
+
+// ConsolidatedBlockNumber returns the latest block whose batch is verified
+func (c *Client) ConsolidatedBlockNumber(t *testing.T, ctx context.Context) (uint64, error) {
+	return c.callUint64(t, ctx, "zkevm_consolidatedBlockNumber")
+}
+
+// BatchNumberByBlockNumber returns the number of the batch containing the block
+func (c *Client) BatchNumberByBlockNumber(t *testing.T, ctx context.Context, blockNumber uint64) (uint64, error) {
+	return c.callUint64(t, ctx, "zkevm_batchNumberByBlockNumber", hex.EncodeUint64(blockNumber))
+}
+
+// IsBlockConsolidated reports whether the batch containing the block is verified
+func (c *Client) IsBlockConsolidated(t *testing.T, ctx context.Context, blockNumber uint64) (bool, error) {
+	var result bool
+	err := c.rpc.CallResult(t, ctx, &result, "zkevm_isBlockConsolidated", hex.EncodeUint64(blockNumber))
+	return result, err
+}
+
+// IsBlockVirtualized reports whether the batch containing the block is sequenced
+func (c *Client) IsBlockVirtualized(t *testing.T, ctx context.Context, blockNumber uint64) (bool, error) {
+	var result bool
+	err := c.rpc.CallResult(t, ctx, &result, "zkevm_isBlockVirtualized", hex.EncodeUint64(blockNumber))
+	return result, err
+}
+
+// LatestGlobalExitRoot returns the latest global exit root used in a batch
+func (c *Client) LatestGlobalExitRoot(t *testing.T, ctx context.Context) (common.Hash, error) {
+	var result common.Hash
+	err := c.rpc.CallResult(t, ctx, &result, "zkevm_getLatestGlobalExitRoot")
+	return result, err
+}
+
+// BatchByNumber returns the batch with the given number, or nil if it
+// doesn't exist. Transactions are returned as hashes unless fullTxs is set.
+func (c *Client) BatchByNumber(t *testing.T, ctx context.Context, batchNumber uint64, fullTxs bool) (*Batch, error) {
+	var result *Batch
+	err := c.rpc.CallResult(t, ctx, &result, "zkevm_getBatchByNumber", hex.EncodeUint64(batchNumber), fullTxs)
+	return result, err
+}
+
+// ExitRootsByGER returns the exit roots that compose the global exit root,
+// or nil if the global exit root is unknown
+func (c *Client) ExitRootsByGER(t *testing.T, ctx context.Context, ger common.Hash) (*ExitRoots, error) {
+	var result *ExitRoots
+	err := c.rpc.CallResult(t, ctx, &result, "zkevm_getExitRootsByGER", ger)
+	return result, err
+}
+
+// EstimateCounters returns the zk counters used by the tx and the limits
+// configured on the node
+func (c *Client) EstimateCounters(t *testing.T, ctx context.Context, args TxArgs) (*CountersResponse, error) {
+	var result *CountersResponse
+	err := c.rpc.CallResult(t, ctx, &result, "zkevm_estimateCounters", args)
+	return result, err
+}
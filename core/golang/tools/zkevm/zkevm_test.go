@@ -0,0 +1,90 @@
+package zkevm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, results map[string]string) *httptest.Server {
+	handlers := map[string]engine.TestHandler{}
+	for method, result := range results {
+		handlers[method] = func(json.RawMessage) (any, error) {
+			return json.RawMessage(result), nil
+		}
+	}
+	return engine.NewTestServer(t, handlers)
+}
+
+func TestClient(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"zkevm_getForkId":               `"0xc"`,
+		"zkevm_verifiedBatchNumber":     `"0x2a"`,
+		"zkevm_isBlockConsolidated":     `true`,
+		"zkevm_getBatchByNumber":        `{"number":"0x2a","closed":true,"sendSequencesTxHash":"0x0000000000000000000000000000000000000000000000000000000000000001","verifyBatchTxHash":null,"blocks":["0x0000000000000000000000000000000000000000000000000000000000000002"],"transactions":[],"timestamp":"0x10"}`,
+		"zkevm_getExitRootsByGER":       `{"blockNumber":"0x5","timestamp":"0x6","mainnetExitRoot":"0x0000000000000000000000000000000000000000000000000000000000000003","rollupExitRoot":"0x0000000000000000000000000000000000000000000000000000000000000004"}`,
+		"zkevm_estimateCounters":        `{"countersUsed":{"gas":21000,"keccakHashes":"0x10","SHA256hashes":"7"},"countersLimits":{"gas":30000000,"keccakHashes":"0x856"},"oocError":"not enough keccak counters to continue the execution"}`,
+		"zkevm_getLatestGlobalExitRoot": `"0x0000000000000000000000000000000000000000000000000000000000000005"`,
+	})
+
+	ctx := context.Background()
+	client := NewClient(server.URL)
+
+	forkID, err := client.ForkID(t, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), forkID)
+
+	verified, err := client.VerifiedBatchNumber(t, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), verified)
+
+	consolidated, err := client.IsBlockConsolidated(t, ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, consolidated)
+
+	batch, err := client.BatchByNumber(t, ctx, 42, false)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), uint64(batch.Number))
+	assert.True(t, batch.IsVirtualized())
+	assert.False(t, batch.IsVerified())
+	blocks, err := batch.BlockHashes()
+	require.NoError(t, err)
+	assert.Equal(t, []common.Hash{common.HexToHash("0x2")}, blocks)
+
+	exitRoots, err := client.ExitRootsByGER(t, ctx, common.HexToHash("0x5"))
+	require.NoError(t, err)
+	assert.Equal(t, common.HexToHash("0x3"), exitRoots.MainnetExitRoot)
+	assert.Equal(t, common.HexToHash("0x4"), exitRoots.RollupExitRoot)
+
+	ger, err := client.LatestGlobalExitRoot(t, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, common.HexToHash("0x5"), ger)
+
+	counters, err := client.EstimateCounters(t, ctx, TxArgs{})
+	require.NoError(t, err)
+	used, found := counters.CountersUsed.Get("keccakhashes")
+	require.True(t, found)
+	assert.Equal(t, uint64(16), used)
+	assert.Equal(t, Quantity(21000), counters.CountersUsed.Gas)
+	assert.Equal(t, Quantity(7), counters.CountersUsed.SHA256Hashes)
+	assert.Equal(t, Quantity(0x856), counters.CountersLimits.KeccakHashes)
+	assert.Equal(t, "not enough keccak counters to continue the execution", counters.FailureReason())
+}
+
+func TestFailureReason(t *testing.T) {
+	counters := CountersResponse{OOCError: "out of counters"}
+	assert.Equal(t, "out of counters", counters.FailureReason())
+
+	// the revert message takes precedence over the out of counters error
+	counters.RevertInfo = &RevertInfo{Message: "execution reverted"}
+	assert.Equal(t, "execution reverted", counters.FailureReason())
+
+	counters.RevertInfo.Message = ""
+	assert.Equal(t, "out of counters", counters.FailureReason())
+}
@@ -0,0 +1,172 @@
+package zkevmbridgeservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/agglayer/e2e/core/golang/tools/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// APIError is returned when the service answers with a non 200 status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the error message.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v - %v", e.StatusCode, e.Message)
+}
+
+// Retryable reports whether the request may succeed later: the deposit is
+// not indexed yet or the service failed, while the other 4xx statuses
+// reject the request itself
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusNotFound || e.StatusCode >= http.StatusInternalServerError
+}
+
+// Client is a typed client for the REST API of the legacy zkevm bridge
+// service
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the http.Client used to send the requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient creates a bridge service client for the provided base URL
+func NewClient(url string, opts ...ClientOption) *Client {
+	c := &Client{
+		url:        strings.TrimSuffix(url, "/"),
+		httpClient: &http.Client{Timeout: engine.DefaultRequestTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// URL returns the base URL of the service
+func (c *Client) URL() string {
+	return c.url
+}
+
+// Deposits returns a page of the deposits sent to the address, newest first
+func (c *Client) Deposits(t *testing.T, ctx context.Context, destAddr common.Address, offset, limit uint64) (*DepositsResult, error) {
+	values := url.Values{}
+	values.Set("offset", strconv.FormatUint(offset, 10))
+	values.Set("limit", strconv.FormatUint(limit, 10))
+	result := &DepositsResult{}
+	err := c.get(t, ctx, BridgesPath+destAddr.String(), values, result)
+	return result, err
+}
+
+// AllDeposits returns every deposit sent to the address, newest first,
+// fetching as many pages as needed
+func (c *Client) AllDeposits(t *testing.T, ctx context.Context, destAddr common.Address) ([]*Deposit, error) {
+	var deposits []*Deposit
+	for {
+		result, err := c.Deposits(t, ctx, destAddr, uint64(len(deposits)), DefaultLimit)
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, result.Deposits...)
+		if len(result.Deposits) == 0 || uint64(len(deposits)) >= uint64(result.TotalCnt) {
+			return deposits, nil
+		}
+	}
+}
+
+// Deposit returns the deposit of the network with the deposit count
+func (c *Client) Deposit(t *testing.T, ctx context.Context, networkID uint32, depositCnt uint64) (*Deposit, error) {
+	values := url.Values{}
+	values.Set("net_id", strconv.FormatUint(uint64(networkID), 10))
+	values.Set("deposit_cnt", strconv.FormatUint(depositCnt, 10))
+	result := &DepositResult{}
+	if err := c.get(t, ctx, BridgePath, values, result); err != nil {
+		return nil, err
+	}
+	return result.Deposit, nil
+}
+
+// MerkleProof returns the proof to claim the deposit of the network with the
+// deposit count
+func (c *Client) MerkleProof(t *testing.T, ctx context.Context, networkID uint32, depositCnt uint64) (*Proof, error) {
+	values := url.Values{}
+	values.Set("deposit_cnt", strconv.FormatUint(depositCnt, 10))
+	values.Set("net_id", strconv.FormatUint(uint64(networkID), 10))
+	result := &ProofResult{}
+	if err := c.get(t, ctx, MerkleProofPath, values, result); err != nil {
+		return nil, err
+	}
+	if result.Proof == nil {
+		return nil, fmt.Errorf("no proof returned for deposit %v of network %v", depositCnt, networkID)
+	}
+	return result.Proof, nil
+}
+
+// ClaimInput returns the params to claim the deposit, requesting its proof
+func (c *Client) ClaimInput(t *testing.T, ctx context.Context, deposit *Deposit) (*ClaimInput, error) {
+	proof, err := c.MerkleProof(t, ctx, deposit.NetworkID, uint64(deposit.DepositCnt))
+	if err != nil {
+		return nil, err
+	}
+	return NewClaimInput(deposit, proof), nil
+}
+
+// get sends a GET request to the path and decodes the JSON body into result
+func (c *Client) get(t *testing.T, ctx context.Context, path string, values url.Values, result any) error {
+	endpoint := c.url + path
+	if len(values) > 0 {
+		endpoint += "?" + values.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	log.Msgf(t, "bridge service call to %v", endpoint)
+	log.Complements(t, fmt.Sprintf("status: %v, took %v", res.StatusCode, time.Since(start)), fmt.Sprintf("response: %v", strings.TrimSpace(string(body))))
+
+	if res.StatusCode != http.StatusOK {
+		var errRes ErrorResponse
+		if json.Unmarshal(body, &errRes) != nil || errRes.Message == "" {
+			errRes.Message = strings.TrimSpace(string(body))
+		}
+		return &APIError{StatusCode: res.StatusCode, Message: errRes.Message}
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to decode response of %v: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package zkevmbridgeservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDestAddr = "0x00000000000000000000000000000000000000de"
+	testTxHash   = "0x0000000000000000000000000000000000000000000000000000000000000001"
+	testDeposit  = `{"leaf_type":0,"orig_net":0,"orig_addr":"0x0000000000000000000000000000000000000000",` +
+		`"amount":"1000000000000000000","dest_net":1,"dest_addr":"` + testDestAddr + `","block_num":"12",` +
+		`"deposit_cnt":"3","network_id":0,"tx_hash":"` + testTxHash + `","claim_tx_hash":"","metadata":"0x",` +
+		`"ready_for_claim":%v,"global_index":"18446744073709551619"}`
+	testClaimABI = `[{"type":"function","name":"claimAsset","stateMutability":"nonpayable","outputs":[],"inputs":[
+		{"name":"smtProofLocalExitRoot","type":"bytes32[32]"},{"name":"smtProofRollupExitRoot","type":"bytes32[32]"},
+		{"name":"globalIndex","type":"uint256"},{"name":"mainnetExitRoot","type":"bytes32"},{"name":"rollupExitRoot","type":"bytes32"},
+		{"name":"originNetwork","type":"uint32"},{"name":"originTokenAddress","type":"address"},{"name":"destinationNetwork","type":"uint32"},
+		{"name":"destinationAddress","type":"address"},{"name":"amount","type":"uint256"},{"name":"metadata","type":"bytes"}]}]`
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	hashes := make([]string, 32) //nolint:mnd
+	for i := range hashes {
+		hashes[i] = `"` + common.BigToHash(common.Big1).String() + `"`
+	}
+	proof := `{"proof":{"merkle_proof":[` + strings.Join(hashes, ",") + `],"rollup_merkle_proof":[` + strings.Join(hashes, ",") + `],` +
+		`"main_exit_root":"0x0000000000000000000000000000000000000000000000000000000000000002",` +
+		`"rollup_exit_root":"0x0000000000000000000000000000000000000000000000000000000000000003"}}`
+
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case BridgesPath + common.HexToAddress(testDestAddr).String():
+			// a single deposit per page to exercise the pagination
+			if query.Get("offset") == "0" {
+				_, _ = w.Write([]byte(`{"deposits":[` + fmt.Sprintf(testDeposit, true) + `],"total_cnt":"2"}`))
+			} else {
+				_, _ = w.Write([]byte(`{"deposits":[` + fmt.Sprintf(testDeposit, false) + `],"total_cnt":"2"}`))
+			}
+		case BridgePath:
+			assert.Equal(t, "3", query.Get("deposit_cnt"))
+			_, _ = w.Write([]byte(`{"deposit":` + fmt.Sprintf(testDeposit, true) + `}`))
+		case MerkleProofPath:
+			if query.Get("deposit_cnt") != "3" || query.Get("net_id") != "0" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"code":5,"message":"deposit not found"}`))
+				return
+			}
+			_, _ = w.Write([]byte(proof))
+		default:
+			t.Errorf("unexpected path %v", r.URL.Path)
+		}
+	})
+	client := NewClient(server.URL)
+
+	deposits, err := client.AllDeposits(t, ctx, common.HexToAddress(testDestAddr))
+	require.NoError(t, err)
+	require.Len(t, deposits, 2)
+	assert.True(t, deposits[0].ReadyForClaim)
+	assert.False(t, deposits[1].ReadyForClaim)
+
+	deposit, err := client.Deposit(t, ctx, 0, 3)
+	require.NoError(t, err)
+	assert.Equal(t, Uint64(3), deposit.DepositCnt)
+	assert.Equal(t, "1000000000000000000", deposit.Amount.String())
+	assert.False(t, deposit.Claimed())
+
+	_, err = client.MerkleProof(t, ctx, 1, 3)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "deposit not found", apiErr.Message)
+
+	input, err := client.ClaimInput(t, ctx, deposit)
+	require.NoError(t, err)
+	assert.Equal(t, "claimAsset", input.Method)
+	assert.Equal(t, common.HexToHash("0x2"), common.Hash(input.MainnetExitRoot))
+	assert.Equal(t, "18446744073709551619", input.GlobalIndex.String())
+
+	parsed, err := abi.JSON(strings.NewReader(testClaimABI))
+	require.NoError(t, err)
+	_, err = parsed.Pack(input.Method, input.Args()...)
+	require.NoError(t, err)
+}
+
+func TestWaitDepositReadyForClaim(t *testing.T) {
+	previous := PollInterval
+	PollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { PollInterval = previous })
+	ctx := context.Background()
+
+	calls := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			_, _ = w.Write([]byte(`{"deposits":[],"total_cnt":"0"}`))
+		case 2: //nolint:mnd
+			_, _ = w.Write([]byte(`{"deposits":[` + fmt.Sprintf(testDeposit, false) + `],"total_cnt":"1"}`))
+		default:
+			_, _ = w.Write([]byte(`{"deposits":[` + fmt.Sprintf(testDeposit, true) + `],"total_cnt":"1"}`))
+		}
+	})
+	client := NewClient(server.URL)
+
+	deposit, err := WaitDepositReadyForClaim(t, ctx, client, common.HexToHash(testTxHash), common.HexToAddress(testDestAddr), time.Second)
+	require.NoError(t, err)
+	assert.True(t, deposit.ReadyForClaim)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWaitDepositReadyForClaimErrors(t *testing.T) {
+	previous := PollInterval
+	PollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { PollInterval = previous })
+	ctx := context.Background()
+
+	// a rejected request stops the wait right away
+	calls := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":3,"message":"invalid address"}`))
+	})
+	_, err := WaitDepositReadyForClaim(t, ctx, NewClient(server.URL), common.HexToHash(testTxHash), common.HexToAddress(testDestAddr), time.Second)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, 1, calls)
+
+	// the failures of the service are retried
+	calls = 0
+	server = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"code":14,"message":"unavailable"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"deposits":[` + fmt.Sprintf(testDeposit, true) + `],"total_cnt":"1"}`))
+	})
+	deposit, err := WaitDepositReadyForClaim(t, ctx, NewClient(server.URL), common.HexToHash(testTxHash), common.HexToAddress(testDestAddr), time.Second)
+	require.NoError(t, err)
+	assert.True(t, deposit.ReadyForClaim)
+	assert.Equal(t, 2, calls) //nolint:mnd
+}
@@ -0,0 +1,191 @@
+package zkevmbridgeservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Paths of the legacy zkevm bridge service REST API
+const (
+	BridgesPath     = "/bridges/"
+	BridgePath      = "/bridge"
+	MerkleProofPath = "/merkle-proof"
+)
+
+// DefaultLimit is the number of deposits requested per page
+const DefaultLimit = 100
+
+// LeafType is the type of a deposit, asset or message
+type LeafType uint32
+
+const (
+	LeafTypeAsset   LeafType = 0
+	LeafTypeMessage LeafType = 1
+)
+
+// Uint64 is an uint64 encoded as a decimal string, as the service encodes
+// the 64 bits fields, numbers are accepted when decoding
+type Uint64 uint64
+
+// MarshalJSON encodes the value as a decimal string
+func (u Uint64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(u), 10))
+}
+
+// UnmarshalJSON decodes decimal strings and numbers
+func (u *Uint64) UnmarshalJSON(input []byte) error {
+	v, err := strconv.ParseUint(strings.Trim(string(input), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid uint64 %v: %w", string(input), err)
+	}
+	*u = Uint64(v)
+	return nil
+}
+
+// BigInt is a big integer encoded as a decimal string
+type BigInt struct {
+	*big.Int
+}
+
+// MarshalJSON encodes the value as a decimal string
+func (b BigInt) MarshalJSON() ([]byte, error) {
+	if b.Int == nil {
+		return json.Marshal("0")
+	}
+	return json.Marshal(b.Int.String())
+}
+
+// UnmarshalJSON decodes decimal strings and numbers
+func (b *BigInt) UnmarshalJSON(input []byte) error {
+	v, ok := new(big.Int).SetString(strings.Trim(string(input), `"`), 10) //nolint:mnd
+	if !ok {
+		return fmt.Errorf("invalid big integer %v", string(input))
+	}
+	b.Int = v
+	return nil
+}
+
+// Deposit is a bridge indexed by the service
+type Deposit struct {
+	LeafType      LeafType       `json:"leaf_type"`
+	OrigNet       uint32         `json:"orig_net"`
+	OrigAddr      common.Address `json:"orig_addr"`
+	Amount        BigInt         `json:"amount"`
+	DestNet       uint32         `json:"dest_net"`
+	DestAddr      common.Address `json:"dest_addr"`
+	BlockNum      Uint64         `json:"block_num"`
+	DepositCnt    Uint64         `json:"deposit_cnt"`
+	NetworkID     uint32         `json:"network_id"`
+	TxHash        common.Hash    `json:"tx_hash"`
+	ClaimTxHash   string         `json:"claim_tx_hash"`
+	Metadata      hexutil.Bytes  `json:"metadata"`
+	ReadyForClaim bool           `json:"ready_for_claim"`
+	GlobalIndex   BigInt         `json:"global_index"`
+}
+
+// Claimed reports whether the service indexed a claim of the deposit
+func (d *Deposit) Claimed() bool {
+	return d.ClaimTxHash != ""
+}
+
+// DepositsResult is a page of the deposits of an address
+type DepositsResult struct {
+	Deposits []*Deposit `json:"deposits"`
+	TotalCnt Uint64     `json:"total_cnt"`
+}
+
+// DepositResult wraps a single deposit
+type DepositResult struct {
+	Deposit *Deposit `json:"deposit"`
+}
+
+// Proof is the merkle proof of a deposit and the exit roots it was built for
+type Proof struct {
+	MerkleProof       [32]common.Hash `json:"merkle_proof"`
+	RollupMerkleProof [32]common.Hash `json:"rollup_merkle_proof"`
+	MainExitRoot      common.Hash     `json:"main_exit_root"`
+	RollupExitRoot    common.Hash     `json:"rollup_exit_root"`
+}
+
+// ProofResult wraps a merkle proof
+type ProofResult struct {
+	Proof *Proof `json:"proof"`
+}
+
+// ErrorResponse is the body returned by the service when a request fails
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ClaimInput are the params of the bridge claimAsset or claimMessage call
+// that claims a deposit
+type ClaimInput struct {
+	SmtProofLocalExitRoot  [32][32]byte
+	SmtProofRollupExitRoot [32][32]byte
+	GlobalIndex            *big.Int
+	MainnetExitRoot        [32]byte
+	RollupExitRoot         [32]byte
+	OriginNetwork          uint32
+	OriginAddress          common.Address
+	DestinationNetwork     uint32
+	DestinationAddress     common.Address
+	Amount                 *big.Int
+	Metadata               []byte
+	// Method is claimAsset or claimMessage depending on the leaf type
+	Method string
+}
+
+// NewClaimInput returns the params to claim the deposit with the proof
+func NewClaimInput(deposit *Deposit, proof *Proof) *ClaimInput {
+	input := &ClaimInput{
+		GlobalIndex:        new(big.Int),
+		MainnetExitRoot:    proof.MainExitRoot,
+		RollupExitRoot:     proof.RollupExitRoot,
+		OriginNetwork:      deposit.OrigNet,
+		OriginAddress:      deposit.OrigAddr,
+		DestinationNetwork: deposit.DestNet,
+		DestinationAddress: deposit.DestAddr,
+		Amount:             new(big.Int),
+		Metadata:           deposit.Metadata,
+		Method:             "claimAsset",
+	}
+	if deposit.GlobalIndex.Int != nil {
+		input.GlobalIndex.Set(deposit.GlobalIndex.Int)
+	}
+	if deposit.Amount.Int != nil {
+		input.Amount.Set(deposit.Amount.Int)
+	}
+	if deposit.LeafType != LeafTypeAsset {
+		input.Method = "claimMessage"
+	}
+	for i := range proof.MerkleProof {
+		input.SmtProofLocalExitRoot[i] = proof.MerkleProof[i]
+		input.SmtProofRollupExitRoot[i] = proof.RollupMerkleProof[i]
+	}
+	return input
+}
+
+// Args returns the params in the order expected by the claim method, e.g.
+// to be sent with bind.BoundContract.Transact(opts, input.Method, input.Args()...)
+func (c *ClaimInput) Args() []any {
+	return []any{
+		c.SmtProofLocalExitRoot,
+		c.SmtProofRollupExitRoot,
+		c.GlobalIndex,
+		c.MainnetExitRoot,
+		c.RollupExitRoot,
+		c.OriginNetwork,
+		c.OriginAddress,
+		c.DestinationNetwork,
+		c.DestinationAddress,
+		c.Amount,
+		c.Metadata,
+	}
+}
@@ -0,0 +1,64 @@
+package zkevmbridgeservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/agglayer/e2e/core/golang/tools/engine"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PollInterval is the time between two checks of the deposit waits
+var PollInterval = 5 * time.Second
+
+// notReady turns the errors worth retrying into NotReady ones: the 404 the
+// service answers while the deposit is not indexed yet, the 5xx and the
+// transport errors. Any other error, like a 400 rejecting the params, stops
+// the wait.
+func notReady(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Retryable() {
+			return engine.NotReady("%v", apiErr.Error())
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return engine.NotReady("%v", err.Error())
+	}
+	return err
+}
+
+// WaitDepositReadyForClaim waits until the deposit sent to destAddr in the tx
+// is ready to be claimed. Only the latest DefaultLimit deposits of the
+// address are checked.
+func WaitDepositReadyForClaim(t *testing.T, ctx context.Context, client *Client, txHash common.Hash, destAddr common.Address, timeout time.Duration) (*Deposit, error) {
+	var deposit *Deposit
+	description := fmt.Sprintf("deposit of tx %v to %v to be ready for claim", txHash.String(), destAddr.String())
+	err := engine.WaitFor(t, ctx, description, engine.Every(PollInterval), timeout, func(ctx context.Context) (bool, error) {
+		result, err := client.Deposits(t, ctx, destAddr, 0, DefaultLimit)
+		if err != nil {
+			return false, notReady(err)
+		}
+
+		for _, d := range result.Deposits {
+			if d.TxHash != txHash {
+				continue
+			}
+			if !d.ReadyForClaim {
+				return false, engine.NotReady("deposit %v of network %v not ready for claim", d.DepositCnt, d.NetworkID)
+			}
+			deposit = d
+			return true, nil
+		}
+		return false, engine.NotReady("deposit not found")
+	})
+	return deposit, err
+}